@@ -0,0 +1,112 @@
+package docblock
+
+import "github.com/MidnightDesign/php-types-go/parser"
+
+// Dialect selects whose rules decide between a plain tag and its
+// tool-specific variants, like @param, @phpstan-param and @psalm-param.
+type Dialect uint8
+
+const (
+	// PHPStan prefers @phpstan- tags over @psalm- tags over plain ones.
+	PHPStan Dialect = iota
+	// Psalm prefers @psalm- tags over @phpstan- tags over plain ones.
+	Psalm
+)
+
+func (d Dialect) String() string {
+	if d == Psalm {
+		return "psalm"
+	}
+	return "phpstan"
+}
+
+var prefixes = []string{"phpstan", "psalm"}
+
+// priority returns how strongly dialect prefers tags with prefix, higher
+// values winning.
+func (d Dialect) priority(prefix string) int {
+	switch prefix {
+	case "":
+		return 0
+	case d.String():
+		return 2
+	}
+	return 1
+}
+
+// EffectiveTags returns the tags of the given kind that apply under dialect.
+// Of the tags that describe the same parameter, property, method or template,
+// only the one with the preferred prefix is kept, so @phpstan-param int $a
+// replaces @param mixed $a but leaves @param string $b alone. The same goes
+// for @return, which describes the function itself. Tags of other kinds, like
+// @throws and @implements, may be repeated and are all kept. Tags whose type
+// cannot be parsed are skipped. The tags are returned in the order of their
+// first declaration.
+func (d *DocBlock) EffectiveTags(kind string, dialect Dialect) []*Tag {
+	var tags []*Tag
+	index := map[string]int{}
+	for _, tag := range d.Tags {
+		if tag.Kind != kind || tag.Err != nil {
+			continue
+		}
+		subject, ok := tag.subject()
+		if !ok {
+			tags = append(tags, tag)
+			continue
+		}
+		i, seen := index[subject]
+		switch {
+		case !seen:
+			index[subject] = len(tags)
+			tags = append(tags, tag)
+		case dialect.priority(tag.Prefix) > dialect.priority(tags[i].Prefix):
+			tags[i] = tag
+		}
+	}
+	return tags
+}
+
+// EffectiveTag returns the effective tag of a kind that appears once, like
+// @return, or nil if there is none.
+func (d *DocBlock) EffectiveTag(kind string, dialect Dialect) *Tag {
+	if tags := d.EffectiveTags(kind, dialect); len(tags) > 0 {
+		return tags[0]
+	}
+	return nil
+}
+
+// EffectiveType returns the type of the effective tag of a kind, or nil.
+func (d *DocBlock) EffectiveType(kind string, dialect Dialect) parser.Node {
+	if tag := d.EffectiveTag(kind, dialect); tag != nil {
+		return tag.Type
+	}
+	return nil
+}
+
+// ParamType returns the effective type of the parameter named variable,
+// without the $, or nil if no tag gives it a type.
+func (d *DocBlock) ParamType(variable string, dialect Dialect) parser.Node {
+	for _, tag := range d.EffectiveTags("param", dialect) {
+		if tag.Variable == variable {
+			return tag.Type
+		}
+	}
+	return nil
+}
+
+// subject identifies what a tag describes, so that the variants of a tag for
+// different tools can be matched up. Tags that may be repeated without
+// describing anything in particular, like @throws, have no subject.
+func (t *Tag) subject() (string, bool) {
+	switch {
+	case t.Method != nil:
+		return t.Method.Name, true
+	case t.Template != nil:
+		return t.Template.Name, true
+	case tagKinds[t.Kind] == variable:
+		return t.Variable, true
+	case t.Kind == "return":
+		return "", true
+	}
+	return "", false
+}
@@ -0,0 +1,127 @@
+package docblock_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/docblock"
+)
+
+const prefixedDocBlock = `/**
+ * @param array $items The items
+ * @param string $key
+ * @psalm-param list<non-empty-string> $items
+ * @phpstan-param list<string> $items
+ * @psalm-param non-empty-string $key
+ * @return array
+ * @phpstan-return array<string, int>
+ * @template T
+ * @psalm-template T of object
+ * @phpstan-param array{broken $other
+ * @phpstan-ignore-next-line
+ */`
+
+func TestTag_Prefix(t *testing.T) {
+	d := mustParse(t, prefixedDocBlock)
+	tests := []struct {
+		name   string
+		prefix string
+		kind   string
+	}{
+		{"param", "", "param"},
+		{"psalm-param", "psalm", "param"},
+		{"phpstan-return", "phpstan", "return"},
+		{"psalm-template", "psalm", "template"},
+		{"phpstan-ignore-next-line", "phpstan", "ignore-next-line"},
+	}
+	for _, test := range tests {
+		tag := d.Tag(test.name)
+		if tag == nil {
+			t.Errorf("Tag(%s) = nil", test.name)
+			continue
+		}
+		if tag.Prefix != test.prefix || tag.Kind != test.kind {
+			t.Errorf("Tag(%s) has prefix %q and kind %q, want %q and %q", test.name, tag.Prefix, tag.Kind, test.prefix, test.kind)
+		}
+	}
+	if got := len(d.TagsNamed("psalm-param")); got != 2 {
+		t.Errorf("TagsNamed(psalm-param) returned %d tags, want 2", got)
+	}
+}
+
+func TestDocBlock_EffectiveTags(t *testing.T) {
+	d := mustParse(t, prefixedDocBlock)
+	tests := []struct {
+		dialect  docblock.Dialect
+		items    string
+		key      string
+		ret      string
+		template string
+	}{
+		{docblock.PHPStan, "list<string>", "non-empty-string", "array<string, int>", "T of object"},
+		{docblock.Psalm, "list<non-empty-string>", "non-empty-string", "array<string, int>", "T of object"},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.String(), func(t *testing.T) {
+			if got := nodeString(d.ParamType("items", test.dialect)); got != test.items {
+				t.Errorf("ParamType(items) = %s, want %s", got, test.items)
+			}
+			if got := nodeString(d.ParamType("key", test.dialect)); got != test.key {
+				t.Errorf("ParamType(key) = %s, want %s", got, test.key)
+			}
+			if got := nodeString(d.ParamType("other", test.dialect)); got != "<nil>" {
+				t.Errorf("ParamType(other) = %s, want <nil>", got)
+			}
+			if got := nodeString(d.EffectiveType("return", test.dialect)); got != test.ret {
+				t.Errorf("EffectiveType(return) = %s, want %s", got, test.ret)
+			}
+			template := d.EffectiveTag("template", test.dialect).Template
+			if got := template.Name + " of " + nodeString(template.Bound); got != test.template {
+				t.Errorf("EffectiveTag(template) = %s, want %s", got, test.template)
+			}
+			params := d.EffectiveTags("param", test.dialect)
+			if len(params) != 2 || params[0].Variable != "items" || params[1].Variable != "key" {
+				t.Errorf("EffectiveTags(param) = %v, want the tags of $items and $key", params)
+			}
+		})
+	}
+}
+
+func TestDocBlock_EffectiveTags_Unprefixed(t *testing.T) {
+	d := mustParse(t, "/**\n * @param int $a\n * @return void\n */")
+	for _, dialect := range []docblock.Dialect{docblock.PHPStan, docblock.Psalm} {
+		if got := nodeString(d.ParamType("a", dialect)); got != "int" {
+			t.Errorf("%s: ParamType(a) = %s, want int", dialect, got)
+		}
+		if d.EffectiveTag("throws", dialect) != nil {
+			t.Errorf("%s: EffectiveTag(throws) should be nil", dialect)
+		}
+	}
+}
+
+func TestDocBlock_EffectiveTags_Repeated(t *testing.T) {
+	d := mustParse(t, `/**
+ * @throws InvalidArgumentException
+ * @throws RuntimeException
+ * @phpstan-throws LogicException
+ * @implements Countable
+ * @implements IteratorAggregate<int, string>
+ */`)
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{"throws", []string{"InvalidArgumentException", "RuntimeException", "LogicException"}},
+		{"implements", []string{"Countable", "IteratorAggregate<int, string>"}},
+	}
+	for _, test := range tests {
+		tags := d.EffectiveTags(test.kind, docblock.PHPStan)
+		var got []string
+		for _, tag := range tags {
+			got = append(got, nodeString(tag.Type))
+		}
+		if strings.Join(got, ", ") != strings.Join(test.want, ", ") {
+			t.Errorf("EffectiveTags(%s) = %v, want %v", test.kind, got, test.want)
+		}
+	}
+}
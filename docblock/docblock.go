@@ -0,0 +1,104 @@
+// Package docblock parses PHPDoc comments into a summary, a description and
+// tags, with the types of tags like @param and @return parsed by the type
+// parser.
+package docblock
+
+import (
+	"fmt"
+	"strings"
+)
+
+type DocBlock struct {
+	Summary     string
+	Description string
+	Tags        []*Tag
+}
+
+// Parse parses a /** ... */ comment. Malformed types in tags do not fail the
+// whole docblock, they are reported on the tag instead.
+func Parse(src string) (*DocBlock, error) {
+	src = strings.TrimSpace(src)
+	if !strings.HasPrefix(src, "/**") || !strings.HasSuffix(src, "*/") || len(src) < len("/***/") {
+		return nil, fmt.Errorf("docblock must start with /** and end with */")
+	}
+	lines := strings.Split(src[len("/**"):len(src)-len("*/")], "\n")
+	for i, line := range lines {
+		lines[i] = stripLine(line)
+	}
+
+	d := &DocBlock{}
+	i := skipBlank(lines, 0)
+	var summary []string
+	for ; i < len(lines) && lines[i] != "" && !isTagLine(lines[i]); i++ {
+		summary = append(summary, lines[i])
+		if strings.HasSuffix(lines[i], ".") {
+			i++
+			break
+		}
+	}
+	d.Summary = strings.Join(summary, "\n")
+
+	i = skipBlank(lines, i)
+	var description []string
+	for ; i < len(lines) && !isTagLine(lines[i]); i++ {
+		description = append(description, lines[i])
+	}
+	d.Description = strings.TrimSpace(strings.Join(description, "\n"))
+
+	var body []string
+	for ; i < len(lines); i++ {
+		if isTagLine(lines[i]) {
+			if body != nil {
+				d.Tags = append(d.Tags, newTag(body))
+			}
+			body = nil
+		}
+		body = append(body, lines[i])
+	}
+	if body != nil {
+		d.Tags = append(d.Tags, newTag(body))
+	}
+	return d, nil
+}
+
+// Tag returns the first tag with the given name, or nil if there is none.
+func (d *DocBlock) Tag(name string) *Tag {
+	for _, tag := range d.Tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+// TagsNamed returns all tags with the given name in the order they appear.
+func (d *DocBlock) TagsNamed(name string) []*Tag {
+	var tags []*Tag
+	for _, tag := range d.Tags {
+		if tag.Name == name {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// stripLine removes the leading asterisk of a docblock line and the space
+// after it.
+func stripLine(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "*") {
+		line = strings.TrimPrefix(line[1:], " ")
+	}
+	return strings.TrimRight(line, " \t\r")
+}
+
+func skipBlank(lines []string, i int) int {
+	for i < len(lines) && lines[i] == "" {
+		i++
+	}
+	return i
+}
+
+func isTagLine(line string) bool {
+	return len(line) > 1 && line[0] == '@'
+}
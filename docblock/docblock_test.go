@@ -0,0 +1,92 @@
+package docblock_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/docblock"
+)
+
+func mustParse(t *testing.T, src string) *docblock.DocBlock {
+	t.Helper()
+	d, err := docblock.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	d := mustParse(t, `/**
+	 * Finds users by role.
+	 *
+	 * The result is ordered by name.
+	 * Inactive users are skipped.
+	 *
+	 * @param Role $role The role
+	 *                   to look for.
+	 * @deprecated use findBy() instead
+	 * @return list<User>
+	 */`)
+	if d.Summary != "Finds users by role." {
+		t.Errorf("Summary = %q", d.Summary)
+	}
+	if want := "The result is ordered by name.\nInactive users are skipped."; d.Description != want {
+		t.Errorf("Description = %q, want %q", d.Description, want)
+	}
+	if len(d.Tags) != 3 {
+		t.Fatalf("got %d tags, want 3", len(d.Tags))
+	}
+	names := []string{"param", "deprecated", "return"}
+	for i, tag := range d.Tags {
+		if tag.Name != names[i] {
+			t.Errorf("tag %d is @%s, want @%s", i, tag.Name, names[i])
+		}
+	}
+	if want := "The role\nto look for."; d.Tags[0].Description != want {
+		t.Errorf("@param description = %q, want %q", d.Tags[0].Description, want)
+	}
+	if d.Tag("deprecated").Body != "use findBy() instead" {
+		t.Errorf("@deprecated body = %q", d.Tag("deprecated").Body)
+	}
+	if d.Tag("throws") != nil {
+		t.Error("Tag(throws) should be nil")
+	}
+}
+
+func TestParse_Summary(t *testing.T) {
+	tests := []struct {
+		src         string
+		summary     string
+		description string
+	}{
+		{"/** Single line. */", "Single line.", ""},
+		{"/** @var int */", "", ""},
+		{"/**\n * First line\n * continues.\n * Description.\n */", "First line\ncontinues.", "Description."},
+		{"/**\n * No period\n *\n * Description\n */", "No period", "Description"},
+		{"/**\n * Summary\n * @return int\n */", "Summary", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			d := mustParse(t, test.src)
+			if d.Summary != test.summary || d.Description != test.description {
+				t.Errorf("Parse() = %q, %q, want %q, %q", d.Summary, d.Description, test.summary, test.description)
+			}
+		})
+	}
+}
+
+func TestParse_TagsNamed(t *testing.T) {
+	d := mustParse(t, "/**\n * @param int $a\n * @return int\n * @param string $b\n */")
+	params := d.TagsNamed("param")
+	if len(params) != 2 || params[0].Variable != "a" || params[1].Variable != "b" {
+		t.Errorf("TagsNamed(param) = %v", params)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, src := range []string{"", "/* not a docblock */", "/** unterminated", "/**/"} {
+		if _, err := docblock.Parse(src); err == nil {
+			t.Errorf("Parse(%q) returned no error", src)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package docblock
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+// Tag is a tag like "@param int $foo The foo". Name is written without the
+// @. Tool-specific tags like @phpstan-param have the tool as Prefix and the
+// rest of the name as Kind, for all other tags Kind is the Name. Which of the
+// other fields are set depends on the kind of tag: @param,
+// @var and @property tags have a type and a variable, @return and @throws only
+// a type, @method tags a Method and @template tags a Template. Body keeps the
+// text after the name for all tags, including unknown ones.
+type Tag struct {
+	Name        string
+	Prefix      string
+	Kind        string
+	Body        string
+	Type        parser.Node
+	Variable    string
+	ByRef       bool
+	Variadic    bool
+	Method      *Method
+	Template    *parser.TemplateParam
+	Description string
+	// Err is set if the type of the tag cannot be parsed, in which case the
+	// whole body is kept as the description.
+	Err error
+}
+
+// Method is the signature declared by a @method tag. The return type, if
+// any, is the Type of the tag.
+type Method struct {
+	Name       string
+	Static     bool
+	Parameters []*parser.ParamNode
+}
+
+type tagKind uint8
+
+const (
+	untyped tagKind = iota
+	typed
+	variable
+	method
+	template
+)
+
+var tagKinds = map[string]tagKind{
+	"param":                  variable,
+	"var":                    variable,
+	"property":               variable,
+	"property-read":          variable,
+	"property-write":         variable,
+	"return":                 typed,
+	"throws":                 typed,
+	"extends":                typed,
+	"implements":             typed,
+	"use":                    typed,
+	"mixin":                  typed,
+	"method":                 method,
+	"template":               template,
+	"template-covariant":     template,
+	"template-contravariant": template,
+}
+
+func newTag(lines []string) *Tag {
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strings.TrimLeft(lines[i], " \t")
+	}
+	text := strings.Join(lines, "\n")[1:]
+	end := strings.IndexFunc(text, func(r rune) bool { return unicode.IsSpace(r) || r == '(' })
+	if end < 0 {
+		end = len(text)
+	}
+	tag := &Tag{Name: text[:end], Kind: text[:end], Body: strings.TrimSpace(text[end:])}
+	for _, prefix := range prefixes {
+		if kind, ok := strings.CutPrefix(tag.Name, prefix+"-"); ok && kind != "" {
+			tag.Prefix, tag.Kind = prefix, kind
+			break
+		}
+	}
+	var err error
+	switch tagKinds[tag.Kind] {
+	case typed:
+		err = tag.parseType()
+	case variable:
+		err = tag.parseVariableType()
+	case method:
+		err = tag.parseMethod()
+	case template:
+		err = tag.parseTemplate()
+	default:
+		tag.Description = tag.Body
+	}
+	if err != nil {
+		tag.Type, tag.Method, tag.Template = nil, nil, nil
+		tag.Variable, tag.ByRef, tag.Variadic = "", false, false
+		tag.Description = tag.Body
+		tag.Err = fmt.Errorf("@%s: %w", tag.Name, err)
+	}
+	return tag
+}
+
+func (t *Tag) parseType() error {
+	node, rest, err := parser.ParsePrefix(t.Body)
+	if err != nil {
+		return err
+	}
+	t.Type = node
+	t.Description = strings.TrimSpace(rest)
+	return nil
+}
+
+// parseVariableType reads "Type $name description", where the type may be
+// left out and the variable may be by-reference or variadic.
+func (t *Tag) parseVariableType() error {
+	rest := t.Body
+	if !startsWithVariable(rest) {
+		node, after, err := parser.ParsePrefix(rest)
+		if err != nil {
+			return err
+		}
+		t.Type = node
+		rest = strings.TrimSpace(after)
+	}
+	t.Variable, t.ByRef, t.Variadic, rest = parseVariable(rest)
+	t.Description = strings.TrimSpace(rest)
+	return nil
+}
+
+func startsWithVariable(s string) bool {
+	s = strings.TrimPrefix(s, "&")
+	s = strings.TrimPrefix(s, "...")
+	return strings.HasPrefix(s, "$")
+}
+
+// parseVariable reads an optional [&][...]$name from the start of s.
+func parseVariable(s string) (name string, byRef, variadic bool, rest string) {
+	if !startsWithVariable(s) {
+		return "", false, false, s
+	}
+	if strings.HasPrefix(s, "&") {
+		byRef, s = true, s[1:]
+	}
+	if strings.HasPrefix(s, "...") {
+		variadic, s = true, s[3:]
+	}
+	s = s[1:]
+	end := strings.IndexFunc(s, func(r rune) bool { return !isNameChar(r) })
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], byRef, variadic, s[end:]
+}
+
+func isNameChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseMethod reads "[static] [ReturnType] name(params) description". Like
+// in PHPStan, "static foo()" declares an instance method returning static.
+func (t *Tag) parseMethod() error {
+	m := &Method{}
+	rest := t.Body
+	if after, ok := strings.CutPrefix(rest, "static"); ok && after != "" && unicode.IsSpace(rune(after[0])) {
+		m.Static, rest = true, strings.TrimSpace(after)
+	}
+	node, after, err := parser.ParsePrefix(rest)
+	if err != nil {
+		return err
+	}
+	after = strings.TrimLeft(after, " \t\n")
+	end := strings.IndexFunc(after, func(r rune) bool { return !isNameChar(r) })
+	switch {
+	case end != 0 && after != "":
+		if end < 0 {
+			end = len(after)
+		}
+		t.Type, m.Name, rest = node, after[:end], after[end:]
+	case isPlainName(node):
+		m.Name, rest = node.(*parser.IdentifierNode).Name, after
+		if m.Static {
+			t.Type, m.Static = parser.NewSimpleNode("static"), false
+		}
+	default:
+		return fmt.Errorf("expected a method name after %s", node)
+	}
+	rest = strings.TrimLeft(rest, " \t\n")
+	if !strings.HasPrefix(rest, "(") {
+		return fmt.Errorf("expected ( after method name %s", m.Name)
+	}
+	closing := matchingParen(rest)
+	if closing < 0 {
+		return fmt.Errorf("unterminated parameter list of method %s", m.Name)
+	}
+	for _, param := range splitParams(rest[1:closing]) {
+		p, err := parseParam(param)
+		if err != nil {
+			return fmt.Errorf("parameter %q of method %s: %w", param, m.Name, err)
+		}
+		m.Parameters = append(m.Parameters, p)
+	}
+	t.Method = m
+	t.Description = strings.TrimSpace(rest[closing+1:])
+	return nil
+}
+
+func isPlainName(node parser.Node) bool {
+	n, ok := node.(*parser.IdentifierNode)
+	return ok && len(n.TypeArguments) == 0 && !strings.Contains(n.Name, "\\")
+}
+
+// parseParam reads a @method parameter like "int &...$values = []". Default
+// values are not types, so only their presence is recorded. Parameters
+// without a type are mixed.
+func parseParam(src string) (*parser.ParamNode, error) {
+	p := &parser.ParamNode{Type: parser.NewSimpleNode("mixed")}
+	rest := src
+	if !startsWithVariable(rest) {
+		node, after, err := parser.ParsePrefix(rest)
+		if err != nil {
+			return nil, err
+		}
+		p.Type = node
+		rest = strings.TrimSpace(after)
+	}
+	if !startsWithVariable(rest) {
+		return nil, fmt.Errorf("expected a parameter name")
+	}
+	p.Name, p.ByRef, p.Variadic, rest = parseVariable(rest)
+	rest = strings.TrimSpace(rest)
+	switch {
+	case strings.HasPrefix(rest, "="):
+		p.Optional = true
+	case rest != "":
+		return nil, fmt.Errorf("unexpected %q after $%s", rest, p.Name)
+	}
+	return p, nil
+}
+
+// matchingParen returns the index of the parenthesis closing the one at the
+// start of s, skipping nested brackets and quoted strings, or -1.
+func matchingParen(s string) int {
+	depth := 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		default:
+			depth += nesting(s, i, r)
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitParams splits a parameter list at the commas that are not nested in
+// brackets or strings.
+func splitParams(s string) []string {
+	var params []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',' && depth == 0:
+			params = append(params, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		default:
+			depth += nesting(s, i, r)
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		params = append(params, last)
+	}
+	return params
+}
+
+// nesting returns how the bracket r at index i of s changes the nesting
+// depth. The > of => and -> does not close anything.
+func nesting(s string, i int, r rune) int {
+	switch r {
+	case '(', '[', '{', '<':
+		return 1
+	case '>':
+		if i > 0 && (s[i-1] == '=' || s[i-1] == '-') {
+			return 0
+		}
+		return -1
+	case ')', ']', '}':
+		return -1
+	}
+	return 0
+}
+
+// parseTemplate reads "T [of|as Bound] [= Default] description".
+func (t *Tag) parseTemplate() error {
+	end := strings.IndexFunc(t.Body, func(r rune) bool { return !isNameChar(r) })
+	if end < 0 {
+		end = len(t.Body)
+	}
+	if end == 0 {
+		return fmt.Errorf("expected a template name")
+	}
+	template := &parser.TemplateParam{Name: t.Body[:end], Variance: variances[t.Kind]}
+	rest := strings.TrimSpace(t.Body[end:])
+	for _, keyword := range []string{"of", "as"} {
+		if after, ok := strings.CutPrefix(rest, keyword+" "); ok {
+			node, after, err := parser.ParsePrefix(after)
+			if err != nil {
+				return err
+			}
+			template.Bound, rest = node, strings.TrimSpace(after)
+			break
+		}
+	}
+	if after, ok := strings.CutPrefix(rest, "="); ok {
+		node, after, err := parser.ParsePrefix(after)
+		if err != nil {
+			return err
+		}
+		template.Default, rest = node, strings.TrimSpace(after)
+	}
+	t.Template = template
+	t.Description = rest
+	return nil
+}
+
+var variances = map[string]parser.Variance{
+	"template-covariant":     parser.Covariant,
+	"template-contravariant": parser.Contravariant,
+}
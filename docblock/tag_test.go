@@ -0,0 +1,201 @@
+package docblock_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/docblock"
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func mustTag(t *testing.T, line string) *docblock.Tag {
+	t.Helper()
+	d := mustParse(t, "/**\n * "+line+"\n */")
+	if len(d.Tags) != 1 {
+		t.Fatalf("got %d tags, want 1", len(d.Tags))
+	}
+	return d.Tags[0]
+}
+
+func nodeString(n parser.Node) string {
+	if n == nil {
+		return "<nil>"
+	}
+	return n.String()
+}
+
+func TestTag_VariableTypes(t *testing.T) {
+	tests := []struct {
+		line        string
+		typ         string
+		variable    string
+		description string
+	}{
+		{"@param int $foo", "int", "$foo", ""},
+		{"@param Foo $foo {@see Bar}", "Foo", "$foo", "{@see Bar}"},
+		{"@param array{a: int, b: string} $shape The shape", "array{a: int, b: string}", "$shape", "The shape"},
+		{"@param int|string &...$values All values.", "int | string", "&...$values", "All values."},
+		{"@param $untyped Without a type", "<nil>", "$untyped", "Without a type"},
+		{"@param callable(int, string): void $callback", "callable(int, string): void", "$callback", ""},
+		{"@param array<\n * int,\n * string\n * > $map", "array<int, string>", "$map", ""},
+		{"@var int", "int", "", ""},
+		{"@var list<User> Users of the group", "list<User>", "", "Users of the group"},
+		{"@var \\App\\User $user", "\\App\\User", "$user", ""},
+		{"@var int[] $x", "array<int>", "$x", ""},
+		{"@param Foo[] $items The items", "array<Foo>", "$items", "The items"},
+		{"@property-read non-empty-string $name", "non-empty-string", "$name", ""},
+		{"@property-write int<0, max> $count don't go negative!", "int<0, max>", "$count", "don't go negative!"},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			tag := mustTag(t, test.line)
+			if tag.Err != nil {
+				t.Fatal(tag.Err)
+			}
+			variable := ""
+			if tag.Variable != "" {
+				variable = "$" + tag.Variable
+				if tag.Variadic {
+					variable = "..." + variable
+				}
+				if tag.ByRef {
+					variable = "&" + variable
+				}
+			}
+			if got := nodeString(tag.Type); got != test.typ || variable != test.variable || tag.Description != test.description {
+				t.Errorf("got %s, %q, %q, want %s, %q, %q", got, variable, tag.Description, test.typ, test.variable, test.description)
+			}
+		})
+	}
+}
+
+func TestTag_Types(t *testing.T) {
+	tests := []struct {
+		line        string
+		typ         string
+		description string
+	}{
+		{"@return ($value is int ? string : null) The result", "($value is int ? string : null)", "The result"},
+		{"@return static", "static", ""},
+		{"@return \\Closure(int): void The callback", "\\Closure(int): void", "The callback"},
+		{"@throws \\InvalidArgumentException If the input is invalid", "\\InvalidArgumentException", "If the input is invalid"},
+		{"@extends Collection<int, User>", "Collection<int, User>", ""},
+		{"@mixin Builder", "Builder", ""},
+		{"@return Foo<int> [deprecated]", "Foo<int>", "[deprecated]"},
+		{"@return array{a: int}['a'] The value", "array{a: int}[\"a\"]", "The value"},
+		{"@return Foo <b>bold</b> text", "Foo", "<b>bold</b> text"},
+		{"@return array {@link https://example.com/docs}", "array", "{@link https://example.com/docs}"},
+		{"@throws RuntimeException {@see Handler::handle()}", "RuntimeException", "{@see Handler::handle()}"},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			tag := mustTag(t, test.line)
+			if tag.Err != nil {
+				t.Fatal(tag.Err)
+			}
+			if got := nodeString(tag.Type); got != test.typ || tag.Description != test.description {
+				t.Errorf("got %s, %q, want %s, %q", got, tag.Description, test.typ, test.description)
+			}
+		})
+	}
+}
+
+func methodString(tag *docblock.Tag) string {
+	m := tag.Method
+	params := make([]string, len(m.Parameters))
+	for i, p := range m.Parameters {
+		params[i] = p.String()
+	}
+	s := fmt.Sprintf("%s(%s): %s", m.Name, strings.Join(params, ", "), nodeString(tag.Type))
+	if m.Static {
+		s = "static " + s
+	}
+	return s
+}
+
+func TestTag_Method(t *testing.T) {
+	tests := []struct {
+		line        string
+		want        string
+		description string
+	}{
+		{"@method int count()", "count(): int", ""},
+		{"@method static self create(string $name, int ...$ids) Factory", "static create(string $name, int ...$ids): self", "Factory"},
+		{"@method static foo()", "foo(): static", ""},
+		{"@method bar($a, &$b = null)", "bar(mixed $a, mixed &$b=): <nil>", ""},
+		{"@method array<string, int> map(array<int, string> $in, callable(int, string): bool $f = null)", "map(array<int, string> $in, callable(int, string): bool $f=): array<string, int>", ""},
+		{"@method void set(array $options = ['a' => 1, 'b' => [2, 3]])", "set(array $options=): void", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			tag := mustTag(t, test.line)
+			if tag.Err != nil {
+				t.Fatal(tag.Err)
+			}
+			if got := methodString(tag); got != test.want || tag.Description != test.description {
+				t.Errorf("got %s, %q, want %s, %q", got, tag.Description, test.want, test.description)
+			}
+		})
+	}
+}
+
+func TestTag_Template(t *testing.T) {
+	tests := []struct {
+		line        string
+		want        string
+		description string
+	}{
+		{"@template T", "T", ""},
+		{"@template T of object The model", "T of object", "The model"},
+		{"@template-covariant TValue as array-key", "covariant TValue of array-key", ""},
+		{"@template-contravariant T of int = 0", "contravariant T of int = 0", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			tag := mustTag(t, test.line)
+			if tag.Err != nil {
+				t.Fatal(tag.Err)
+			}
+			template := tag.Template
+			got := template.Name
+			if template.Variance != parser.Invariant {
+				got = template.Variance.String() + " " + got
+			}
+			if template.Bound != nil {
+				got += " of " + template.Bound.String()
+			}
+			if template.Default != nil {
+				got += " = " + template.Default.String()
+			}
+			if got != test.want || tag.Description != test.description {
+				t.Errorf("got %s, %q, want %s, %q", got, tag.Description, test.want, test.description)
+			}
+		})
+	}
+}
+
+func TestTag_Errors(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"@param array{a: int $foo", "@param: unexpected $foo (1:14-1:17), expected } or ,"},
+		{"@return", "@return: unexpected end of input, expected a type"},
+		{"@method list<int> (", "@method: expected a method name after list<int>"},
+		{"@method int foo(int $a", "@method: unterminated parameter list of method foo"},
+		{"@method int foo(int)", "@method: parameter \"int\" of method foo: expected a parameter name"},
+		{"@template", "@template: expected a template name"},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			tag := mustTag(t, test.line)
+			if tag.Err == nil || tag.Err.Error() != test.want {
+				t.Errorf("Err = %v, want %s", tag.Err, test.want)
+			}
+			if tag.Type != nil || tag.Description != tag.Body {
+				t.Errorf("a tag with an error should keep its body as description, got %s, %q", nodeString(tag.Type), tag.Description)
+			}
+		})
+	}
+}
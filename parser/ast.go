@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -19,32 +20,70 @@ type CurlyListNode struct {
 	Elements []Node
 }
 type CurlyKeyValueNode struct {
-	Name    string
-	Members []*MemberNode
+	Name       string
+	Members    []*MemberNode
+	Sealed     bool
+	ExtraKey   Node
+	ExtraValue Node
 }
 
+type KeyKind uint8
+
+const (
+	IdentifierKey KeyKind = iota
+	StringKey
+	IntKey
+)
+
 type MemberNode struct {
 	Key      string
+	KeyKind  KeyKind
 	Value    Node
 	Optional bool
+	Loc      Span
 }
 
 func NewMember(key string, value Node) *MemberNode {
-	return &MemberNode{Key: key, Value: value, Optional: false}
+	return &MemberNode{Key: key, KeyKind: keyKindOf(key), Value: value, Optional: false}
 }
 
 func NewOptionalMember(key string, value Node) *MemberNode {
-	return &MemberNode{Key: key, Value: value, Optional: true}
+	return &MemberNode{Key: key, KeyKind: keyKindOf(key), Value: value, Optional: true}
+}
+
+// keyKindOf returns the kind of key that reads back as key when printed.
+func keyKindOf(key string) KeyKind {
+	if i, err := strconv.Atoi(key); err == nil && strconv.Itoa(i) == key {
+		return IntKey
+	}
+	if isIdentifier(key) {
+		return IdentifierKey
+	}
+	return StringKey
 }
 
 func (n MemberNode) String() string {
 	if n.Optional {
-		return fmt.Sprintf("%s?: %s", n.Key, n.Value)
+		return fmt.Sprintf("%s?: %s", FormatKey(n.Key, n.KeyKind), n.Value)
+	}
+	return fmt.Sprintf("%s: %s", FormatKey(n.Key, n.KeyKind), n.Value)
+}
+
+// FormatKey prints a shape key, quoting it unless it reads back as the same
+// key without quotes.
+func FormatKey(key string, kind KeyKind) string {
+	if kind == IntKey || isIdentifier(key) {
+		return key
+	}
+	if strings.ContainsRune(key, '"') {
+		return fmt.Sprintf("'%s'", key)
 	}
-	return fmt.Sprintf("%s: %s", n.Key, n.Value)
+	return fmt.Sprintf("\"%s\"", key)
 }
 
 type CallableNode struct {
+	Name       string
+	Templates  []*TemplateParam
 	ReturnType Node
 	Parameters []*ParamNode
 }
@@ -52,6 +91,10 @@ type CallableNode struct {
 type ParamNode struct {
 	Type     Node
 	Optional bool
+	Variadic bool
+	ByRef    bool
+	Name     string
+	Loc      Span
 }
 
 func NewParam(typeNode Node) *ParamNode {
@@ -62,11 +105,28 @@ func NewOptionalParam(typeNode Node) *ParamNode {
 	return &ParamNode{Type: typeNode, Optional: true}
 }
 
+func NewVariadicParam(typeNode Node) *ParamNode {
+	return &ParamNode{Type: typeNode, Variadic: true}
+}
+
 func (n *ParamNode) String() string {
+	s := n.Type.String()
+	if n.ByRef || n.Variadic || n.Name != "" {
+		s += " "
+		if n.ByRef {
+			s += "&"
+		}
+		if n.Variadic {
+			s += "..."
+		}
+		if n.Name != "" {
+			s += "$" + n.Name
+		}
+	}
 	if n.Optional {
-		return fmt.Sprintf("%s=", n.Type.String())
+		s += "="
 	}
-	return n.Type.String()
+	return s
 }
 
 type StringLiteralNode struct {
@@ -85,6 +145,35 @@ type IntersectionNode struct {
 	Elements []Node
 }
 
+type VarianceNode struct {
+	Variance Variance
+	Type     Node
+}
+
+type WildcardNode struct{}
+
+type ConstFetchNode struct {
+	Class string
+	Name  string
+}
+
+type VariableNode struct {
+	Name string
+}
+
+type ConditionalNode struct {
+	Subject Node
+	Target  Node
+	Negated bool
+	If      Node
+	Else    Node
+}
+
+type OffsetAccessNode struct {
+	Type   Node
+	Offset Node
+}
+
 func nodeList(list []Node) string {
 	if len(list) == 0 {
 		return ""
@@ -112,15 +201,40 @@ func (n CurlyKeyValueNode) String() string {
 	for i, member := range n.Members {
 		members[i] = member.String()
 	}
+	if !n.Sealed {
+		members = append(members, unsealed(n.ExtraKey, n.ExtraValue))
+	}
 	return fmt.Sprintf("%s{%s}", n.Name, strings.Join(members, ", "))
 }
 
+// unsealed prints the marker for the extra keys of an unsealed shape.
+func unsealed(key, value Node) string {
+	switch {
+	case value == nil:
+		return "..."
+	case key == nil:
+		return fmt.Sprintf("...<%s>", value)
+	}
+	return fmt.Sprintf("...<%s, %s>", key, value)
+}
+
 func (n CallableNode) String() string {
 	parameters := make([]string, len(n.Parameters))
 	for i, parameter := range n.Parameters {
 		parameters[i] = parameter.String()
 	}
-	return fmt.Sprintf("callable(%s): %s", strings.Join(parameters, ", "), n.ReturnType)
+	name := n.Name
+	if name == "" {
+		name = "callable"
+	}
+	if len(n.Templates) > 0 {
+		templates := make([]string, len(n.Templates))
+		for i, template := range n.Templates {
+			templates[i] = template.String()
+		}
+		name = fmt.Sprintf("%s<%s>", name, strings.Join(templates, ", "))
+	}
+	return fmt.Sprintf("%s(%s): %s", name, strings.Join(parameters, ", "), parenthesize(n.ReturnType))
 }
 
 func (n StringLiteralNode) String() string {
@@ -143,10 +257,54 @@ func (n IntersectionNode) String() string {
 	elements := make([]string, len(n.Elements))
 	for i, element := range n.Elements {
 		elements[i] = element.String()
+		if _, ok := element.(*UnionNode); ok {
+			elements[i] = fmt.Sprintf("(%s)", element)
+		}
 	}
 	return strings.Join(elements, " & ")
 }
 
+func (n VarianceNode) String() string {
+	return fmt.Sprintf("%s %s", n.Variance, n.Type)
+}
+
+func (n WildcardNode) String() string {
+	return "*"
+}
+
+func (n ConstFetchNode) String() string {
+	return fmt.Sprintf("%s::%s", n.Class, n.Name)
+}
+
+func (n VariableNode) String() string {
+	return "$" + n.Name
+}
+
+func (n ConditionalNode) String() string {
+	is := "is"
+	if n.Negated {
+		is = "is not"
+	}
+	return fmt.Sprintf("(%s %s %s ? %s : %s)", n.Subject, is, n.Target, n.If, n.Else)
+}
+
+func (n OffsetAccessNode) String() string {
+	if _, ok := n.Type.(*CallableNode); ok {
+		return fmt.Sprintf("(%s)[%s]", n.Type, n.Offset)
+	}
+	return fmt.Sprintf("%s[%s]", parenthesize(n.Type), n.Offset)
+}
+
+// parenthesize wraps unions and intersections in parentheses, so that they
+// are not mistaken for a part of the surrounding type.
+func parenthesize(node Node) string {
+	switch node.(type) {
+	case *UnionNode, *IntersectionNode:
+		return fmt.Sprintf("(%s)", node)
+	}
+	return node.String()
+}
+
 func NewSimpleNode(name string) Node {
 	return &IdentifierNode{Name: name}
 }
@@ -160,11 +318,32 @@ func NewCurlyListNode(name string, elements []Node) Node {
 }
 
 func NewCurlyKeyValueNode(name string, members []*MemberNode) Node {
-	return &CurlyKeyValueNode{Name: name, Members: members}
+	return &CurlyKeyValueNode{Name: name, Members: members, Sealed: true}
+}
+
+func NewUnsealedCurlyKeyValueNode(name string, members []*MemberNode, extraKey, extraValue Node) Node {
+	return &CurlyKeyValueNode{Name: name, Members: members, ExtraKey: extraKey, ExtraValue: extraValue}
 }
 
 func NewCallableNode(returnType Node, parameters []*ParamNode) Node {
-	return &CallableNode{ReturnType: returnType, Parameters: parameters}
+	return &CallableNode{Name: "callable", ReturnType: returnType, Parameters: parameters}
+}
+
+func NewNamedCallableNode(name string, returnType Node, parameters []*ParamNode) Node {
+	return &CallableNode{Name: name, ReturnType: returnType, Parameters: parameters}
+}
+
+// IsCallableName reports whether name starts a callable type like
+// Closure(int): void. Like class names, it is case-insensitive and may have a
+// leading backslash.
+func IsCallableName(name string) bool {
+	name = strings.TrimPrefix(name, "\\")
+	for _, callable := range []string{"callable", "Closure", "pure-callable", "pure-Closure"} {
+		if strings.EqualFold(name, callable) {
+			return true
+		}
+	}
+	return false
 }
 
 func NewStringLiteralNode(value string) Node {
@@ -184,3 +363,31 @@ func NewIntersectionNode(first Node, second Node, other ...Node) Node {
 	elements := append([]Node{first, second}, other...)
 	return &IntersectionNode{Elements: elements}
 }
+
+func NewVarianceNode(variance Variance, typeNode Node) Node {
+	return &VarianceNode{Variance: variance, Type: typeNode}
+}
+
+func NewWildcardNode() Node {
+	return &WildcardNode{}
+}
+
+func NewConstFetchNode(class, name string) Node {
+	return &ConstFetchNode{Class: class, Name: name}
+}
+
+func NewVariableNode(name string) Node {
+	return &VariableNode{Name: name}
+}
+
+func NewConditionalNode(subject, target, ifNode, elseNode Node) Node {
+	return &ConditionalNode{Subject: subject, Target: target, If: ifNode, Else: elseNode}
+}
+
+func NewNegatedConditionalNode(subject, target, ifNode, elseNode Node) Node {
+	return &ConditionalNode{Subject: subject, Target: target, Negated: true, If: ifNode, Else: elseNode}
+}
+
+func NewOffsetAccessNode(typeNode Node, offset Node) Node {
+	return &OffsetAccessNode{Type: typeNode, Offset: offset}
+}
@@ -60,6 +60,14 @@ func TestNode_String(t *testing.T) {
 			}),
 			want: "array{foo: string, bar: int}",
 		},
+		{
+			node: parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+				parser.NewMember("with space", parser.NewSimpleNode("string")),
+				{Key: "0", KeyKind: parser.StringKey, Value: parser.NewSimpleNode("int")},
+				{Key: "1", KeyKind: parser.IntKey, Value: parser.NewSimpleNode("int")},
+			}),
+			want: "array{\"with space\": string, \"0\": int, 1: int}",
+		},
 		{
 			node: parser.NewCurlyKeyValueNode("object", []*parser.MemberNode{
 				parser.NewOptionalMember("foo", parser.NewSimpleNode("string")),
@@ -120,6 +128,35 @@ func TestNode_String(t *testing.T) {
 			),
 			want: "array{foo: string} & array{bar: int}",
 		},
+		{
+			node: parser.NewIntersectionNode(
+				parser.NewUnionNode(parser.NewSimpleNode("Foo"), parser.NewSimpleNode("Bar")),
+				parser.NewSimpleNode("Baz"),
+			),
+			want: "(Foo | Bar) & Baz",
+		},
+		{
+			node: parser.NewCallableNode(
+				parser.NewUnionNode(parser.NewSimpleNode("int"), parser.NewSimpleNode("null")),
+				nil,
+			),
+			want: "callable(): (int | null)",
+		},
+		{
+			node: parser.NewGenericNode("Collection", []parser.Node{
+				parser.NewVarianceNode(parser.Covariant, parser.NewSimpleNode("Foo")),
+				parser.NewWildcardNode(),
+			}),
+			want: "Collection<covariant Foo, *>",
+		},
+		{
+			node: parser.NewConstFetchNode("Foo", "BAR"),
+			want: "Foo::BAR",
+		},
+		{
+			node: parser.NewConstFetchNode("Foo", "STATUS_*"),
+			want: "Foo::STATUS_*",
+		},
 	}
 
 	for _, test := range tests {
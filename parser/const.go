@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+func (n ConstFetchNode) IsWildcard() bool {
+	return strings.Contains(n.Name, "*")
+}
+
+// Expand resolves the constant reference against the constants of its
+// class. A wildcard reference expands to the union of the values of all
+// matching constants, in the order of their names.
+func (n ConstFetchNode) Expand(constants map[string]Node) (Node, error) {
+	if !n.IsWildcard() {
+		value, ok := constants[n.Name]
+		if !ok {
+			return nil, fmt.Errorf("undefined constant %s", n)
+		}
+		return value, nil
+	}
+	var names []string
+	for name := range constants {
+		if matched, _ := path.Match(n.Name, name); matched {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	switch len(names) {
+	case 0:
+		return nil, fmt.Errorf("no constants match %s", n)
+	case 1:
+		return constants[names[0]], nil
+	}
+	elements := make([]Node, len(names))
+	for i, name := range names {
+		elements[i] = constants[name]
+	}
+	return &UnionNode{Elements: elements}, nil
+}
@@ -0,0 +1,54 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func TestConstFetchNode_Expand(t *testing.T) {
+	constants := map[string]parser.Node{
+		"STATUS_ACTIVE":   parser.NewStringLiteralNode("active"),
+		"STATUS_INACTIVE": parser.NewStringLiteralNode("inactive"),
+		"LIMIT":           parser.NewIntLiteralNode(10),
+	}
+	tests := []struct {
+		node *parser.ConstFetchNode
+		want string
+	}{
+		{&parser.ConstFetchNode{Class: "Foo", Name: "LIMIT"}, "10"},
+		{&parser.ConstFetchNode{Class: "Foo", Name: "STATUS_*"}, "\"active\" | \"inactive\""},
+		{&parser.ConstFetchNode{Class: "Foo", Name: "*_ACTIVE"}, "\"active\""},
+		{&parser.ConstFetchNode{Class: "Foo", Name: "*"}, "10 | \"active\" | \"inactive\""},
+	}
+	for _, test := range tests {
+		t.Run(test.node.String(), func(t *testing.T) {
+			got, err := test.node.Expand(constants)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != test.want {
+				t.Errorf("Expand(%s) = %s, want %s", test.node, got, test.want)
+			}
+		})
+	}
+}
+
+func TestConstFetchNode_Expand_Errors(t *testing.T) {
+	constants := map[string]parser.Node{"LIMIT": parser.NewIntLiteralNode(10)}
+	tests := []struct {
+		node *parser.ConstFetchNode
+		want string
+	}{
+		{&parser.ConstFetchNode{Class: "Foo", Name: "MISSING"}, "undefined constant Foo::MISSING"},
+		{&parser.ConstFetchNode{Class: "Foo", Name: "STATUS_*"}, "no constants match Foo::STATUS_*"},
+	}
+	for _, test := range tests {
+		t.Run(test.node.String(), func(t *testing.T) {
+			_, err := test.node.Expand(constants)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("Expand(%s) error = %v, want %s", test.node, err, test.want)
+			}
+		})
+	}
+}
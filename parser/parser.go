@@ -0,0 +1,635 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+type parser struct {
+	tokens []Token
+	pos    int
+	// prefix is set when parsing a type followed by other text, where a [
+	// after whitespace starts that text instead of an offset access.
+	prefix bool
+}
+
+func Parse(src string) (Node, error) {
+	t := newTokenizer(src)
+	tokens := t.tokenize()
+	if t.err != nil {
+		return nil, t.err
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := p.peek(); ok {
+		return nil, unexpected(token, "end of input")
+	}
+	return node, nil
+}
+
+// ParsePrefix parses the type at the start of src and returns it along with
+// the text that follows it, like the variable name and description in
+// "array{a: int, b: string} $foo The foo". Tokenizing stops at the first
+// character that cannot be part of a type.
+func ParsePrefix(src string) (Node, string, error) {
+	t := newTokenizer(src)
+	t.lenient = true
+	tokens := t.tokenize()
+	if t.err != nil && len(tokens) == 0 {
+		return nil, src, t.err
+	}
+	p := &parser{tokens: tokens, prefix: true}
+	node, err := p.parseType()
+	if err != nil {
+		return nil, src, err
+	}
+	return node, src[offsetAfter(src, p.end()):], nil
+}
+
+// offsetAfter returns the byte offset of the character following loc.
+func offsetAfter(src string, loc Location) int {
+	current := Location{Line: 1, Col: 1}
+	for i, char := range src {
+		if current == loc {
+			return i + utf8.RuneLen(char)
+		}
+		if char == '\n' {
+			current = Location{Line: current.Line + 1, Col: 1}
+		} else {
+			current.Col++
+		}
+	}
+	return len(src)
+}
+
+func (p *parser) peek() (Token, bool) {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) (Token, bool) {
+	if p.pos+offset >= len(p.tokens) {
+		return Token{}, false
+	}
+	return p.tokens[p.pos+offset], true
+}
+
+func (p *parser) peekKind(kind tokenKind) bool {
+	token, ok := p.peek()
+	return ok && token.Kind == kind
+}
+
+func (p *parser) next() (Token, error) {
+	token, ok := p.peek()
+	if !ok {
+		return Token{}, fmt.Errorf("unexpected end of input")
+	}
+	p.pos++
+	return token, nil
+}
+
+func (p *parser) expect(kind tokenKind) (Token, error) {
+	token, err := p.next()
+	if err != nil {
+		return Token{}, fmt.Errorf("unexpected end of input, expected %s", kind)
+	}
+	if token.Kind != kind {
+		return Token{}, unexpected(token, kind.String())
+	}
+	return token, nil
+}
+
+// end returns the location where the last consumed token ends.
+func (p *parser) end() Location {
+	return p.tokens[p.pos-1].Loc.End
+}
+
+func unexpected(token Token, expected string) error {
+	return fmt.Errorf("unexpected %s, expected %s", token, expected)
+}
+
+func (p *parser) parseType() (Node, error) {
+	first, err := p.parseIntersection()
+	if err != nil {
+		return nil, err
+	}
+	elements := []Node{first}
+	for p.peekKind(Pipe) {
+		p.pos++
+		element, err := p.parseIntersection()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	if len(elements) == 1 {
+		return first, nil
+	}
+	return &UnionNode{Elements: elements}, nil
+}
+
+func (p *parser) parseIntersection() (Node, error) {
+	first, err := p.parseOffsetAccess()
+	if err != nil {
+		return nil, err
+	}
+	elements := []Node{first}
+	for p.peekIntersection() {
+		p.pos++
+		element, err := p.parseOffsetAccess()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	if len(elements) == 1 {
+		return first, nil
+	}
+	return &IntersectionNode{Elements: elements}, nil
+}
+
+// peekIntersection reports whether the next token is an & that continues an
+// intersection rather than marking a by-reference callable parameter.
+func (p *parser) peekIntersection() bool {
+	if !p.peekKind(Amp) {
+		return false
+	}
+	following, ok := p.peekAt(1)
+	return !ok || following.Kind != Variable && following.Kind != Ellipsis &&
+		following.Kind != Eq && following.Kind != Comma && following.Kind != Rparen
+}
+
+func (p *parser) parseOffsetAccess() (Node, error) {
+	node, err := p.parseAtomic()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKind(Lbracket) && (!p.prefix || p.adjacent()) {
+		p.pos++
+		if p.peekKind(Rbracket) {
+			p.pos++
+			node = NewGenericNode("array", []Node{node})
+			continue
+		}
+		offset, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(Rbracket); err != nil {
+			return nil, err
+		}
+		node = NewOffsetAccessNode(node, offset)
+	}
+	return node, nil
+}
+
+// adjacent reports whether the next token directly follows the previous one,
+// without any whitespace in between.
+func (p *parser) adjacent() bool {
+	previous, next := p.tokens[p.pos-1].Loc.End, p.tokens[p.pos].Loc.Start
+	return next.Line == previous.Line && next.Col == previous.Col+1
+}
+
+func (p *parser) parseAtomic() (Node, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected end of input, expected a type")
+	}
+	switch token.Kind {
+	case StringLiteral:
+		return NewStringLiteralNode(token.Val), nil
+	case IntLiteral:
+		value, err := strconv.Atoi(token.Val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer literal %s", token)
+		}
+		return NewIntLiteralNode(value), nil
+	case Lparen:
+		return p.parseParenthesized()
+	case Identifier:
+		return p.parseIdentifier(token)
+	}
+	return nil, unexpected(token, "a type")
+}
+
+func (p *parser) parseParenthesized() (Node, error) {
+	var node Node
+	if variable, ok := p.peek(); ok && variable.Kind == Variable {
+		p.pos++
+		node = NewVariableNode(variable.Val)
+		if !p.peekIdentifier("is") {
+			token, ok := p.peek()
+			if !ok {
+				return nil, fmt.Errorf("unexpected end of input, expected is")
+			}
+			return nil, unexpected(token, "is")
+		}
+	} else {
+		var err error
+		node, err = p.parseType()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.peekIdentifier("is") {
+		p.pos++
+		var err error
+		node, err = p.parseConditional(node)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.expect(Rparen); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (p *parser) peekIdentifier(name string) bool {
+	token, ok := p.peek()
+	return ok && token.Kind == Identifier && token.Val == name
+}
+
+func (p *parser) parseConditional(subject Node) (Node, error) {
+	negated := false
+	if following, ok := p.peekAt(1); ok && following.Kind != Question && p.peekIdentifier("not") {
+		p.pos++
+		negated = true
+	}
+	target, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(Question); err != nil {
+		return nil, err
+	}
+	ifNode, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(Colon); err != nil {
+		return nil, err
+	}
+	elseNode, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	return &ConditionalNode{Subject: subject, Target: target, Negated: negated, If: ifNode, Else: elseNode}, nil
+}
+
+func (p *parser) parseIdentifier(name Token) (Node, error) {
+	next, ok := p.peek()
+	if !ok {
+		return NewSimpleNode(name.Val), nil
+	}
+	// In a docblock, a < or { after whitespace starts the description, like
+	// <b>bold</b> or {@link Foo}, rather than type arguments or a shape.
+	if p.prefix && (next.Kind == Lt || next.Kind == Lbrace) && !p.adjacent() {
+		return NewSimpleNode(name.Val), nil
+	}
+	switch next.Kind {
+	case Lt:
+		p.pos++
+		if IsCallableName(name.Val) {
+			return p.parseGenericCallable(name.Val)
+		}
+		arguments, err := p.parseTypeArguments()
+		if err != nil {
+			return nil, err
+		}
+		return NewGenericNode(name.Val, arguments), nil
+	case Lbrace:
+		p.pos++
+		return p.parseCurly(name.Val)
+	case Lparen:
+		if !IsCallableName(name.Val) {
+			break
+		}
+		p.pos++
+		return p.parseCallable(name.Val)
+	case DoubleColon:
+		p.pos++
+		return p.parseConstFetch(name.Val)
+	}
+	return NewSimpleNode(name.Val), nil
+}
+
+func (p *parser) parseConstFetch(class string) (Node, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected end of input, expected a constant name")
+	}
+	switch token.Kind {
+	case Identifier:
+		return NewConstFetchNode(class, token.Val), nil
+	case Asterisk:
+		// The tokenizer does not treat a leading * as part of an identifier,
+		// so patterns like Foo::*_SUFFIX arrive as two adjacent tokens.
+		if suffix, ok := p.peek(); ok && suffix.Kind == Identifier && suffix.Loc.Start == token.Loc.End.add(1) {
+			p.pos++
+			return NewConstFetchNode(class, "*"+suffix.Val), nil
+		}
+		return NewConstFetchNode(class, "*"), nil
+	}
+	return nil, unexpected(token, "a constant name")
+}
+
+func (p *parser) parseTypeArguments() ([]Node, error) {
+	var arguments []Node
+	for {
+		argument, err := p.parseTypeArgument()
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+		token, err := p.next()
+		if err != nil {
+			return nil, fmt.Errorf("unexpected end of input, expected > or ,")
+		}
+		switch token.Kind {
+		case Gt:
+			return arguments, nil
+		case Comma:
+			if p.peekKind(Gt) {
+				p.pos++
+				return arguments, nil
+			}
+			continue
+		}
+		return nil, unexpected(token, "> or ,")
+	}
+}
+
+func (p *parser) parseTypeArgument() (Node, error) {
+	if p.peekKind(Asterisk) {
+		p.pos++
+		return NewWildcardNode(), nil
+	}
+	token, _ := p.peek()
+	following, hasFollowing := p.peekAt(1)
+	startsType := hasFollowing && (following.Kind == Identifier || following.Kind == StringLiteral ||
+		following.Kind == IntLiteral || following.Kind == Lparen)
+	if token.Kind == Identifier && startsType {
+		switch token.Val {
+		case "covariant":
+			p.pos++
+			node, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			return NewVarianceNode(Covariant, node), nil
+		case "contravariant":
+			p.pos++
+			node, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			return NewVarianceNode(Contravariant, node), nil
+		}
+	}
+	return p.parseType()
+}
+
+func (p *parser) parseCurly(name string) (Node, error) {
+	var elements []Node
+	var members []*MemberNode
+	for !p.peekKind(Rbrace) {
+		if p.peekKind(Ellipsis) {
+			return p.parseUnsealed(name, elements, members)
+		}
+		if p.isMemberKey() {
+			if elements != nil {
+				return nil, fmt.Errorf("cannot mix keyed and positional elements in %s{...}", name)
+			}
+			member, err := p.parseMember()
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		} else {
+			if members != nil {
+				return nil, fmt.Errorf("cannot mix keyed and positional elements in %s{...}", name)
+			}
+			element, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			if p.peekKind(Question) {
+				return nil, fmt.Errorf("optional elements need an explicit key in %s{...}, like %d?: %s", name, len(elements), element)
+			}
+			elements = append(elements, element)
+		}
+		if p.peekKind(Comma) {
+			p.pos++
+			continue
+		}
+		if !p.peekKind(Rbrace) {
+			token, ok := p.peek()
+			if !ok {
+				return nil, fmt.Errorf("unexpected end of input, expected } or ,")
+			}
+			return nil, unexpected(token, "} or ,")
+		}
+	}
+	p.pos++
+	if elements != nil {
+		return NewCurlyListNode(name, elements), nil
+	}
+	node := &CurlyKeyValueNode{Name: name, Members: members, Sealed: true}
+	if err := node.Validate(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// parseUnsealed parses the trailing ... or ...<K, V> of an unsealed shape.
+// Positional elements before it become members keyed by their position.
+func (p *parser) parseUnsealed(name string, elements []Node, members []*MemberNode) (Node, error) {
+	p.pos++
+	node := &CurlyKeyValueNode{Name: name, Members: members}
+	if elements != nil {
+		node = (&CurlyListNode{Name: name, Elements: elements}).Keyed()
+		node.Sealed = false
+	}
+	if p.peekKind(Lt) {
+		p.pos++
+		arguments, err := p.parseTypeArguments()
+		if err != nil {
+			return nil, err
+		}
+		switch len(arguments) {
+		case 1:
+			node.ExtraValue = arguments[0]
+		case 2:
+			node.ExtraKey, node.ExtraValue = arguments[0], arguments[1]
+		default:
+			return nil, fmt.Errorf("... expects 1 or 2 type arguments, got %d in %s{...}", len(arguments), name)
+		}
+	}
+	if p.peekKind(Comma) {
+		p.pos++
+	}
+	if _, err := p.expect(Rbrace); err != nil {
+		return nil, err
+	}
+	if err := node.Validate(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (p *parser) isMemberKey() bool {
+	key, ok := p.peek()
+	if !ok || key.Kind != Identifier && key.Kind != StringLiteral && key.Kind != IntLiteral {
+		return false
+	}
+	following, ok := p.peekAt(1)
+	if ok && following.Kind == Question {
+		following, ok = p.peekAt(2)
+	}
+	return ok && following.Kind == Colon
+}
+
+var keyKinds = map[tokenKind]KeyKind{
+	Identifier:    IdentifierKey,
+	StringLiteral: StringKey,
+	IntLiteral:    IntKey,
+}
+
+func (p *parser) parseMember() (*MemberNode, error) {
+	key, _ := p.next()
+	optional := false
+	if p.peekKind(Question) {
+		p.pos++
+		optional = true
+	}
+	p.pos++
+	value, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	return &MemberNode{
+		Key:      key.Val,
+		KeyKind:  keyKinds[key.Kind],
+		Value:    value,
+		Optional: optional,
+		Loc:      NewSpan(key.Loc.Start, p.end()),
+	}, nil
+}
+
+func (p *parser) parseGenericCallable(name string) (Node, error) {
+	var templates []*TemplateParam
+	for {
+		template, err := p.parseTemplateParam()
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+		token, err := p.next()
+		if err != nil {
+			return nil, fmt.Errorf("unexpected end of input, expected > or ,")
+		}
+		if token.Kind == Gt {
+			break
+		}
+		if token.Kind != Comma {
+			return nil, unexpected(token, "> or ,")
+		}
+		if p.peekKind(Gt) {
+			p.pos++
+			break
+		}
+	}
+	if _, err := p.expect(Lparen); err != nil {
+		return nil, err
+	}
+	node, err := p.parseCallable(name)
+	if err != nil {
+		return nil, err
+	}
+	node.(*CallableNode).Templates = templates
+	return node, nil
+}
+
+func (p *parser) parseTemplateParam() (*TemplateParam, error) {
+	name, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected end of input, expected a template name")
+	}
+	if name.Kind != Identifier {
+		return nil, unexpected(name, "a template name")
+	}
+	template := &TemplateParam{Name: name.Val}
+	if p.peekIdentifier("of") {
+		p.pos++
+		if template.Bound, err = p.parseType(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekKind(Eq) {
+		p.pos++
+		if template.Default, err = p.parseType(); err != nil {
+			return nil, err
+		}
+	}
+	return template, nil
+}
+
+func (p *parser) parseCallable(name string) (Node, error) {
+	var parameters []*ParamNode
+	for !p.peekKind(Rparen) {
+		if len(parameters) > 0 && parameters[len(parameters)-1].Variadic {
+			return nil, fmt.Errorf("variadic parameter must be the last parameter of %s", name)
+		}
+		start, ok := p.peek()
+		if !ok {
+			return nil, fmt.Errorf("unexpected end of input, expected a parameter")
+		}
+		typeNode, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		parameter := NewParam(typeNode)
+		if p.peekKind(Amp) {
+			p.pos++
+			parameter.ByRef = true
+		}
+		if p.peekKind(Ellipsis) {
+			p.pos++
+			parameter.Variadic = true
+		}
+		if variable, ok := p.peek(); ok && variable.Kind == Variable {
+			p.pos++
+			parameter.Name = variable.Val
+		}
+		if p.peekKind(Eq) {
+			p.pos++
+			parameter.Optional = true
+		}
+		parameter.Loc = NewSpan(start.Loc.Start, p.end())
+		parameters = append(parameters, parameter)
+		if p.peekKind(Comma) {
+			p.pos++
+			continue
+		}
+		if !p.peekKind(Rparen) {
+			token, ok := p.peek()
+			if !ok {
+				return nil, fmt.Errorf("unexpected end of input, expected ) or ,")
+			}
+			return nil, unexpected(token, ") or ,")
+		}
+	}
+	p.pos++
+	if _, err := p.expect(Colon); err != nil {
+		return nil, err
+	}
+	returnType, err := p.parseOffsetAccess()
+	if err != nil {
+		return nil, err
+	}
+	return NewNamedCallableNode(name, returnType, parameters), nil
+}
@@ -0,0 +1,249 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"string", "string"},
+		{"list<string>", "list<string>"},
+		{"array<array-key, list<int>>", "array<array-key, list<int>>"},
+		{"array<int, string,>", "array<int, string>"},
+		{"array{}", "array{}"},
+		{"array{int, string}", "array{int, string}"},
+		{"array{foo: int, bar?: string}", "array{foo: int, bar?: string}"},
+		{"array{\n    foo: string,\n}", "array{foo: string}"},
+		{"object{foo: int}", "object{foo: int}"},
+		{"callable(): void", "callable(): void"},
+		{"callable(string, int=): bool", "callable(string, int=): bool"},
+		{"callable(): int | null", "callable(): int | null"},
+		{"callable(): (int | null)", "callable(): (int | null)"},
+		{"'foo'", "\"foo\""},
+		{"-42", "-42"},
+		{"string|int|null", "string | int | null"},
+		{"Foo&Bar|Baz", "Foo & Bar | Baz"},
+		{"\\App\\Collection<App\\User>", "\\App\\Collection<App\\User>"},
+		{"\\App\\Status::ACTIVE", "\\App\\Status::ACTIVE"},
+		{"(Foo|Bar)&Baz", "(Foo | Bar) & Baz"},
+		{"Collection<covariant Foo>", "Collection<covariant Foo>"},
+		{"Collection<contravariant Foo, *>", "Collection<contravariant Foo, *>"},
+		{"Collection<covariant>", "Collection<covariant>"},
+		{"Foo::BAR", "Foo::BAR"},
+		{"Foo::*", "Foo::*"},
+		{"Foo::*_ACTIVE", "Foo::*_ACTIVE"},
+		{"Foo::STATUS_* | null", "Foo::STATUS_* | null"},
+		{"list<Foo::BAR>", "list<Foo::BAR>"},
+		{"Config['host']", "Config[\"host\"]"},
+		{"T[K]", "T[K]"},
+		{"array{foo: int}['foo']", "array{foo: int}[\"foo\"]"},
+		{"T['a'][0]", "T[\"a\"][0]"},
+		{"(A | B)['a']", "(A | B)[\"a\"]"},
+		{"A | B['a']", "A | B[\"a\"]"},
+		{"(callable(): array{a: int})['a']", "(callable(): array{a: int})[\"a\"]"},
+		{"callable(): int[]", "callable(): array<int>"},
+		{"callable(): T['k']", "callable(): T[\"k\"]"},
+		{"int[]", "array<int>"},
+		{"int[][]", "array<array<int>>"},
+		{"(int | string)[]", "array<int | string>"},
+		{"T['a'][]", "array<T[\"a\"]>"},
+		{"(T is string ? int : bool)", "(T is string ? int : bool)"},
+		{"($param is true ? A : B)", "($param is true ? A : B)"},
+		{"($param is not null ? A|B : never)", "($param is not null ? A | B : never)"},
+		{"(T is int|float ? list<T> : (T is string ? string : null))", "(T is int | float ? list<T> : (T is string ? string : null))"},
+		{"(not is not ? A : B)", "(not is not ? A : B)"},
+		{"callable(): (T is int ? int : void)", "callable(): (T is int ? int : void)"},
+		{"callable(int ...$rest): void", "callable(int ...$rest): void"},
+		{"callable(int...): void", "callable(int ...): void"},
+		{"callable(string &$out, int $x=): void", "callable(string &$out, int $x=): void"},
+		{"callable(Foo&Bar &...$xs): void", "callable(Foo & Bar &...$xs): void"},
+		{"callable(Foo&Bar): void", "callable(Foo & Bar): void"},
+		{"Closure(int $x): bool", "Closure(int $x): bool"},
+		{"pure-callable(): int", "pure-callable(): int"},
+		{"pure-Closure(): int", "pure-Closure(): int"},
+		{"closure(int): void", "closure(int): void"},
+		{"\\Closure(int): void", "\\Closure(int): void"},
+		{"Closure", "Closure"},
+		{"callable<T>(T $x): T", "callable<T>(T $x): T"},
+		{"array{'foo-bar': int, 0: string, \"with space\": bool}", "array{foo-bar: int, 0: string, \"with space\": bool}"},
+		{"array{'0': int, 1: string}", "array{\"0\": int, 1: string}"},
+		{"array{'a\"b': int}", "array{'a\"b': int}"},
+		{"array{key1: int, 'a.b'?: string}", "array{key1: int, \"a.b\"?: string}"},
+		{"list{int, string}", "list{int, string}"},
+		{"list{0: int, 1?: string}", "list{0: int, 1?: string}"},
+		{"list{int, ...<string>}", "list{0: int, ...<string>}"},
+		{"array{foo: int, ...}", "array{foo: int, ...}"},
+		{"array{foo: int, ...,}", "array{foo: int, ...}"},
+		{"array{...}", "array{...}"},
+		{"array{foo: int, ...<string, mixed>}", "array{foo: int, ...<string, mixed>}"},
+		{"array{foo: int, ...<mixed>}", "array{foo: int, ...<mixed>}"},
+		{"array{int, string, ...}", "array{0: int, 1: string, ...}"},
+		{"Closure<K of array-key, V = mixed,>(array<K, V>): list<K>", "Closure<K of array-key, V = mixed>(array<K, V>): list<K>"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			node, err := parser.Parse(test.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", test.src, err)
+			}
+			if got := node.String(); got != test.want {
+				t.Errorf("Parse(%q) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParse_Structure(t *testing.T) {
+	node, err := parser.Parse("callable(): int | null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	union, ok := node.(*parser.UnionNode)
+	if !ok || len(union.Elements) != 2 {
+		t.Fatalf("expected a union of a callable and null, got %#v", node)
+	}
+	if _, ok := union.Elements[0].(*parser.CallableNode); !ok {
+		t.Errorf("expected a callable, got %#v", union.Elements[0])
+	}
+
+	node, err = parser.Parse("Collection<covariant Foo, *>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	arguments := node.(*parser.IdentifierNode).TypeArguments
+	if variance, ok := arguments[0].(*parser.VarianceNode); !ok || variance.Variance != parser.Covariant {
+		t.Errorf("expected a covariant type argument, got %#v", arguments[0])
+	}
+	if _, ok := arguments[1].(*parser.WildcardNode); !ok {
+		t.Errorf("expected a wildcard type argument, got %#v", arguments[1])
+	}
+}
+
+func TestParse_Locations(t *testing.T) {
+	node, err := parser.Parse("array{\n    foo: string,\n    bar?: list<int>,\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := node.(*parser.CurlyKeyValueNode).Members
+	if want := parser.NewSpanFromInts(2, 5, 2, 15); members[0].Loc != want {
+		t.Errorf("expected member foo at %s, got %s", want, members[0].Loc)
+	}
+	if want := parser.NewSpanFromInts(3, 5, 3, 19); members[1].Loc != want {
+		t.Errorf("expected member bar at %s, got %s", want, members[1].Loc)
+	}
+
+	node, err = parser.Parse("callable(string, int=): void")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parameters := node.(*parser.CallableNode).Parameters
+	if want := parser.NewSpanFromInts(1, 10, 1, 15); parameters[0].Loc != want {
+		t.Errorf("expected parameter 1 at %s, got %s", want, parameters[0].Loc)
+	}
+	if want := parser.NewSpanFromInts(1, 18, 1, 21); parameters[1].Loc != want {
+		t.Errorf("expected parameter 2 at %s, got %s", want, parameters[1].Loc)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", "unexpected end of input, expected a type"},
+		{"list<int", "unexpected end of input, expected > or ,"},
+		{"list<>", "unexpected > (1:6-1:6), expected a type"},
+		{"int string", "unexpected string (1:5-1:10), expected end of input"},
+		{"array{int, foo: string}", "cannot mix keyed and positional elements in array{...}"},
+		{"callable(int)", "unexpected end of input, expected :"},
+		{"string | \"foo", "unterminated string literal"},
+		{"string | 023", "integer literal cannot have leading zero"},
+		{"int#", "unexpected character # at 1:4"},
+		{"App\\", "expected a name after \\ at 1:4"},
+		{"\\\\App", "expected a name after \\ at 1:1"},
+		{"Foo::", "unexpected end of input, expected a constant name"},
+		{"$param", "unexpected $param (1:1-1:6), expected a type"},
+		{"($param)", "unexpected ) (1:8-1:8), expected is"},
+		{"(T is string : int)", "unexpected : (1:14-1:14), expected ?"},
+		{"(T is string ? int)", "unexpected ) (1:19-1:19), expected :"},
+		{"($ is int ? A : B)", "expected a variable name after $ at 1:2"},
+		{"callable(int ...$a, int $b): void", "variadic parameter must be the last parameter of callable"},
+		{"callable(int ..): void", "unexpected character . at 1:14"},
+		{"callable<T>: void", "unexpected : (1:12-1:12), expected ("},
+		{"array{..., foo: int}", "unexpected foo (1:12-1:14), expected }"},
+		{"array{int, string?}", "optional elements need an explicit key in array{...}, like 1?: string"},
+		{"array{int, foo: string}", "cannot mix keyed and positional elements in array{...}"},
+		{"array{foo: string, int}", "cannot mix keyed and positional elements in array{...}"},
+		{"list{foo: int}", "expected key 0, got foo in list{foo: int}"},
+		{"list{1: int}", "expected key 0, got 1 in list{1: int}"},
+		{"list{0?: int, 1: string}", "optional key 0 must not be followed by required keys in list{0?: int, 1: string}"},
+		{"list{int, ...<int, string>}", "the extra elements of a list shape only take a value type in list{0: int, ...<int, string>}"},
+		{"array{...<int, string, bool>}", "... expects 1 or 2 type arguments, got 3 in array{...}"},
+		{"callable<'T'>(): void", "unexpected \"T\" (1:10-1:12), expected a template name"},
+		{"T['a'", "unexpected end of input, expected ]"},
+		{"Foo::'bar'", "unexpected \"bar\" (1:6-1:10), expected a constant name"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			_, err := parser.Parse(test.src)
+			if err == nil {
+				t.Fatalf("Parse(%q) returned no error", test.src)
+			}
+			if err.Error() != test.want {
+				t.Errorf("Parse(%q) error = %q, want %q", test.src, err, test.want)
+			}
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+		rest string
+	}{
+		{"int", "int", ""},
+		{"int $foo", "int", " $foo"},
+		{"array{a: int, b: string} $foo The foo.", "array{a: int, b: string}", " $foo The foo."},
+		{"int|string &...$values", "int | string", " &...$values"},
+		{"Foo & Bar $x", "Foo & Bar", " $x"},
+		{"callable(int): void The callback", "callable(int): void", " The callback"},
+		{"array<\n  int,\n  string\n> $map", "array<int, string>", " $map"},
+		{"list<string> don't use #1!", "list<string>", " don't use #1!"},
+		{"\\App\\User ünïcode", "\\App\\User", " ünïcode"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			node, rest, err := parser.ParsePrefix(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if node.String() != test.want || rest != test.rest {
+				t.Errorf("ParsePrefix(%q) = %s, %q, want %s, %q", test.src, node, rest, test.want, test.rest)
+			}
+		})
+	}
+}
+
+func TestParsePrefix_Errors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"$foo", "unexpected $foo (1:1-1:4), expected a type"},
+		{"array{a: int $foo", "unexpected $foo (1:14-1:17), expected } or ,"},
+		{"#", "unexpected end of input, expected a type"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if _, _, err := parser.ParsePrefix(test.src); err == nil || err.Error() != test.want {
+				t.Errorf("ParsePrefix(%q) error = %v, want %s", test.src, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Keyed returns the equivalent shape with the implicit keys 0..n-1 written
+// out as int keys.
+func (n *CurlyListNode) Keyed() *CurlyKeyValueNode {
+	members := make([]*MemberNode, len(n.Elements))
+	for i, element := range n.Elements {
+		members[i] = &MemberNode{Key: strconv.Itoa(i), KeyKind: IntKey, Value: element}
+	}
+	return &CurlyKeyValueNode{Name: n.Name, Members: members, Sealed: true}
+}
+
+// Validate checks the members of the shape against the rules of its kind.
+// The keys of a list shape must be 0..n-1 in order, with optional keys only
+// at the end. Object shapes describe properties, so they can neither have
+// numeric keys nor be unsealed.
+func (n *CurlyKeyValueNode) Validate() error {
+	switch n.Name {
+	case "list":
+		return n.validateList()
+	case "object":
+		return n.validateObject()
+	}
+	return nil
+}
+
+func (n *CurlyKeyValueNode) validateObject() error {
+	for _, member := range n.Members {
+		if _, err := strconv.Atoi(member.Key); err == nil || member.KeyKind == IntKey {
+			return fmt.Errorf("object shapes cannot have the numeric key %s in %s", FormatKey(member.Key, member.KeyKind), n)
+		}
+	}
+	if !n.Sealed {
+		return fmt.Errorf("object shapes cannot be unsealed in %s", n)
+	}
+	return nil
+}
+
+func (n *CurlyKeyValueNode) validateList() error {
+	for i, member := range n.Members {
+		if member.Key != strconv.Itoa(i) {
+			return fmt.Errorf("expected key %d, got %s in %s", i, FormatKey(member.Key, member.KeyKind), n)
+		}
+		if member.Optional && i+1 < len(n.Members) && !n.Members[i+1].Optional {
+			return fmt.Errorf("optional key %d must not be followed by required keys in %s", i, n)
+		}
+	}
+	if n.ExtraKey != nil {
+		return fmt.Errorf("the extra elements of a list shape only take a value type in %s", n)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func TestCurlyListNode_Keyed(t *testing.T) {
+	node := parser.NewCurlyListNode("list", []parser.Node{parser.NewSimpleNode("int"), parser.NewSimpleNode("string")})
+	keyed := node.(*parser.CurlyListNode).Keyed()
+	if got, want := keyed.String(), "list{0: int, 1: string}"; got != want {
+		t.Errorf("Keyed() = %s, want %s", got, want)
+	}
+	for i, member := range keyed.Members {
+		if member.KeyKind != parser.IntKey {
+			t.Errorf("Keyed().Members[%d].KeyKind = %v, want IntKey", i, member.KeyKind)
+		}
+	}
+	if err := keyed.Validate(); err != nil {
+		t.Errorf("Keyed().Validate() = %v", err)
+	}
+}
+
+func TestCurlyKeyValueNode_Validate(t *testing.T) {
+	tests := []struct {
+		node parser.Node
+		want string
+	}{
+		{parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{parser.NewMember("foo", parser.NewSimpleNode("int"))}), ""},
+		{parser.NewCurlyKeyValueNode("list", nil), ""},
+		{
+			parser.NewCurlyKeyValueNode("list", []*parser.MemberNode{parser.NewMember("foo", parser.NewSimpleNode("int"))}),
+			"expected key 0, got foo in list{foo: int}",
+		},
+		{
+			parser.NewCurlyKeyValueNode("list", []*parser.MemberNode{
+				parser.NewMember("0", parser.NewSimpleNode("int")),
+				parser.NewMember("2", parser.NewSimpleNode("int")),
+			}),
+			"expected key 1, got 2 in list{0: int, 2: int}",
+		},
+		{
+			parser.NewCurlyKeyValueNode("object", []*parser.MemberNode{parser.NewMember("foo", parser.NewSimpleNode("int"))}),
+			"",
+		},
+		{
+			parser.NewCurlyKeyValueNode("object", []*parser.MemberNode{parser.NewMember("0", parser.NewSimpleNode("int"))}),
+			"object shapes cannot have the numeric key 0 in object{0: int}",
+		},
+		{
+			parser.NewCurlyKeyValueNode("object", []*parser.MemberNode{{Key: "1", KeyKind: parser.StringKey, Value: parser.NewSimpleNode("int")}}),
+			"object shapes cannot have the numeric key \"1\" in object{\"1\": int}",
+		},
+		{
+			parser.NewUnsealedCurlyKeyValueNode("object", []*parser.MemberNode{parser.NewMember("foo", parser.NewSimpleNode("int"))}, nil, nil),
+			"object shapes cannot be unsealed in object{foo: int, ...}",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.node.String(), func(t *testing.T) {
+			err := test.node.(*parser.CurlyKeyValueNode).Validate()
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != test.want {
+				t.Errorf("Validate() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
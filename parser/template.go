@@ -0,0 +1,147 @@
+package parser
+
+import "fmt"
+
+type Variance uint8
+
+const (
+	Invariant Variance = iota
+	Covariant
+	Contravariant
+	Bivariant
+)
+
+func (v Variance) String() string {
+	switch v {
+	case Covariant:
+		return "covariant"
+	case Contravariant:
+		return "contravariant"
+	case Bivariant:
+		return "bivariant"
+	}
+	return "invariant"
+}
+
+type TemplateParam struct {
+	Name     string
+	Bound    Node
+	Default  Node
+	Variance Variance
+}
+
+func NewTemplateParam(name string, bound Node) *TemplateParam {
+	return &TemplateParam{Name: name, Bound: bound}
+}
+
+func (p *TemplateParam) String() string {
+	s := p.Name
+	if p.Variance != Invariant {
+		s = fmt.Sprintf("%s %s", p.Variance, s)
+	}
+	if p.Bound != nil {
+		s = fmt.Sprintf("%s of %s", s, p.Bound)
+	}
+	if p.Default != nil {
+		s = fmt.Sprintf("%s = %s", s, p.Default)
+	}
+	return s
+}
+
+// Substitute returns a copy of node in which every reference to a template
+// name in substitutions is replaced with the corresponding node.
+func Substitute(node Node, substitutions map[string]Node) Node {
+	switch n := node.(type) {
+	case *IdentifierNode:
+		if len(n.TypeArguments) == 0 {
+			if substitution, ok := substitutions[n.Name]; ok {
+				return substitution
+			}
+			return n
+		}
+		return &IdentifierNode{Name: n.Name, TypeArguments: substituteList(n.TypeArguments, substitutions)}
+	case *CurlyListNode:
+		return &CurlyListNode{Name: n.Name, Elements: substituteList(n.Elements, substitutions)}
+	case *CurlyKeyValueNode:
+		members := make([]*MemberNode, len(n.Members))
+		for i, member := range n.Members {
+			substituted := *member
+			substituted.Value = Substitute(member.Value, substitutions)
+			members[i] = &substituted
+		}
+		substituted := &CurlyKeyValueNode{Name: n.Name, Members: members, Sealed: n.Sealed}
+		if n.ExtraKey != nil {
+			substituted.ExtraKey = Substitute(n.ExtraKey, substitutions)
+		}
+		if n.ExtraValue != nil {
+			substituted.ExtraValue = Substitute(n.ExtraValue, substitutions)
+		}
+		return substituted
+	case *CallableNode:
+		substitutions = shadow(substitutions, n.Templates)
+		templates := make([]*TemplateParam, len(n.Templates))
+		for i, template := range n.Templates {
+			substituted := *template
+			if template.Bound != nil {
+				substituted.Bound = Substitute(template.Bound, substitutions)
+			}
+			if template.Default != nil {
+				substituted.Default = Substitute(template.Default, substitutions)
+			}
+			templates[i] = &substituted
+		}
+		parameters := make([]*ParamNode, len(n.Parameters))
+		for i, parameter := range n.Parameters {
+			substituted := *parameter
+			substituted.Type = Substitute(parameter.Type, substitutions)
+			parameters[i] = &substituted
+		}
+		return &CallableNode{
+			Name:       n.Name,
+			Templates:  templates,
+			ReturnType: Substitute(n.ReturnType, substitutions),
+			Parameters: parameters,
+		}
+	case *UnionNode:
+		return &UnionNode{Elements: substituteList(n.Elements, substitutions)}
+	case *IntersectionNode:
+		return &IntersectionNode{Elements: substituteList(n.Elements, substitutions)}
+	case *VarianceNode:
+		return &VarianceNode{Variance: n.Variance, Type: Substitute(n.Type, substitutions)}
+	case *ConditionalNode:
+		return &ConditionalNode{
+			Subject: Substitute(n.Subject, substitutions),
+			Target:  Substitute(n.Target, substitutions),
+			Negated: n.Negated,
+			If:      Substitute(n.If, substitutions),
+			Else:    Substitute(n.Else, substitutions),
+		}
+	case *OffsetAccessNode:
+		return &OffsetAccessNode{Type: Substitute(n.Type, substitutions), Offset: Substitute(n.Offset, substitutions)}
+	}
+	return node
+}
+
+// shadow returns substitutions without the templates that a generic callable
+// declares itself, since its own parameters hide the outer ones.
+func shadow(substitutions map[string]Node, templates []*TemplateParam) map[string]Node {
+	if len(templates) == 0 {
+		return substitutions
+	}
+	shadowed := make(map[string]Node, len(substitutions))
+	for name, substitution := range substitutions {
+		shadowed[name] = substitution
+	}
+	for _, template := range templates {
+		delete(shadowed, template.Name)
+	}
+	return shadowed
+}
+
+func substituteList(nodes []Node, substitutions map[string]Node) []Node {
+	substituted := make([]Node, len(nodes))
+	for i, node := range nodes {
+		substituted[i] = Substitute(node, substitutions)
+	}
+	return substituted
+}
@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func TestTemplateParam_String(t *testing.T) {
+	tests := []struct {
+		param *parser.TemplateParam
+		want  string
+	}{
+		{parser.NewTemplateParam("T", nil), "T"},
+		{parser.NewTemplateParam("T", parser.NewSimpleNode("object")), "T of object"},
+		{
+			&parser.TemplateParam{Name: "T", Bound: parser.NewSimpleNode("int"), Default: parser.NewIntLiteralNode(0)},
+			"T of int = 0",
+		},
+		{&parser.TemplateParam{Name: "T", Variance: parser.Covariant}, "covariant T"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.param.String(); got != test.want {
+				t.Errorf("TemplateParam.String() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	substitutions := map[string]parser.Node{
+		"T": parser.NewSimpleNode("int"),
+		"U": parser.NewUnionNode(parser.NewSimpleNode("string"), parser.NewSimpleNode("null")),
+	}
+	tests := []struct {
+		node parser.Node
+		want string
+	}{
+		{parser.NewSimpleNode("T"), "int"},
+		{parser.NewSimpleNode("V"), "V"},
+		{parser.NewGenericNode("list", []parser.Node{parser.NewSimpleNode("T")}), "list<int>"},
+		{parser.NewGenericNode("T", []parser.Node{parser.NewSimpleNode("U")}), "T<string | null>"},
+		{parser.NewCurlyListNode("array", []parser.Node{parser.NewSimpleNode("T"), parser.NewSimpleNode("U")}), "array{int, string | null}"},
+		{
+			parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+				parser.NewOptionalMember("T", parser.NewSimpleNode("T")),
+			}),
+			"array{T?: int}",
+		},
+		{
+			parser.NewCallableNode(parser.NewSimpleNode("U"), []*parser.ParamNode{
+				parser.NewOptionalParam(parser.NewSimpleNode("T")),
+			}),
+			"callable(int=): (string | null)",
+		},
+		{parser.NewIntersectionNode(parser.NewSimpleNode("T"), parser.NewSimpleNode("Foo")), "int & Foo"},
+		{
+			&parser.CallableNode{
+				Name:       "callable",
+				Templates:  []*parser.TemplateParam{parser.NewTemplateParam("T", parser.NewSimpleNode("U"))},
+				ReturnType: parser.NewSimpleNode("U"),
+				Parameters: []*parser.ParamNode{parser.NewParam(parser.NewSimpleNode("T"))},
+			},
+			"callable<T of string | null>(T): (string | null)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.node.String(), func(t *testing.T) {
+			if got := parser.Substitute(test.node, substitutions); got.String() != test.want {
+				t.Errorf("Substitute(%s) = %s, want %s", test.node, got, test.want)
+			}
+		})
+	}
+}
@@ -15,6 +15,10 @@ func (t tokenKind) String() string {
 		return "StringLiteral"
 	case IntLiteral:
 		return "IntLiteral"
+	case Variable:
+		return "Variable"
+	case Ellipsis:
+		return "..."
 	case Gt:
 		return ">"
 	case Lt:
@@ -41,6 +45,12 @@ func (t tokenKind) String() string {
 		return "::"
 	case Asterisk:
 		return "*"
+	case Question:
+		return "?"
+	case Lbracket:
+		return "["
+	case Rbracket:
+		return "]"
 	}
 	return "unknown"
 }
@@ -62,6 +72,11 @@ const (
 	Eq
 	DoubleColon
 	Asterisk
+	Question
+	Lbracket
+	Rbracket
+	Variable
+	Ellipsis
 )
 
 type Token struct {
@@ -79,6 +94,8 @@ func (t Token) String() string {
 		v = fmt.Sprintf("\"%s\"", t.Val)
 	case IntLiteral:
 		v = fmt.Sprintf("%s", t.Val)
+	case Variable:
+		v = "$" + t.Val
 	default:
 		v = t.Kind.String()
 	}
@@ -97,6 +114,10 @@ func NewIntLiteralToken(value int, loc Span) Token {
 	return Token{Kind: IntLiteral, Val: strconv.Itoa(value), Loc: loc}
 }
 
+func NewVariableToken(name string, loc Span) Token {
+	return Token{Kind: Variable, Val: name, Loc: loc}
+}
+
 func NewSymbolToken(kind tokenKind, loc Span) Token {
 	return Token{Kind: kind, Loc: loc}
 }
@@ -8,17 +8,27 @@ import (
 type tokenizer struct {
 	chars []rune
 	loc   Location
+	err   error
+	// lenient makes the tokenizer stop at the first unexpected character
+	// instead of reporting it, for types that are followed by free text.
+	lenient bool
 }
 
 func (t *tokenizer) tokenize() []Token {
 	var tokens []Token
+loop:
 	for {
 		if len(t.chars) == 0 {
 			break
 		}
 		char := t.char()
-		if isIdentifierFirstChar(char) {
-			tokens = append(tokens, t.identifier())
+		if isIdentifierFirstChar(char) || char == '\\' {
+			name, err := t.qualifiedName()
+			if err != nil {
+				t.err = err
+				break
+			}
+			tokens = append(tokens, name)
 			continue
 		}
 		if unicode.IsSpace(char) {
@@ -28,6 +38,7 @@ func (t *tokenizer) tokenize() []Token {
 		if char == '"' || char == '\'' {
 			literal, err := t.stringLiteral()
 			if err != nil {
+				t.err = err
 				break
 			}
 			tokens = append(tokens, literal)
@@ -36,11 +47,30 @@ func (t *tokenizer) tokenize() []Token {
 		if (char >= '0' && char <= '9') || char == '-' {
 			literal, err := t.intLiteral()
 			if err != nil {
+				t.err = err
 				break
 			}
 			tokens = append(tokens, literal)
 			continue
 		}
+		if char == '$' {
+			variable, err := t.variable()
+			if err != nil {
+				t.err = err
+				break
+			}
+			tokens = append(tokens, variable)
+			continue
+		}
+		if char == '.' {
+			ellipsis, err := t.ellipsis()
+			if err != nil {
+				t.err = err
+				break
+			}
+			tokens = append(tokens, ellipsis)
+			continue
+		}
 		if char == ':' {
 			start := t.loc
 			t.next()
@@ -76,6 +106,19 @@ func (t *tokenizer) tokenize() []Token {
 			tokens = append(tokens, NewSymbolToken(Eq, span))
 		case '*':
 			tokens = append(tokens, NewSymbolToken(Asterisk, span))
+		case '?':
+			tokens = append(tokens, NewSymbolToken(Question, span))
+		case '[':
+			tokens = append(tokens, NewSymbolToken(Lbracket, span))
+		case ']':
+			tokens = append(tokens, NewSymbolToken(Rbracket, span))
+		default:
+			if t.lenient {
+				break loop
+			}
+			if t.err == nil {
+				t.err = fmt.Errorf("unexpected character %c at %s", char, t.loc)
+			}
 		}
 		t.next()
 	}
@@ -104,12 +147,69 @@ func (t *tokenizer) identifier() Token {
 	return Token{Kind: Identifier, Val: string(name), Loc: NewSpan(start, end)}
 }
 
+// qualifiedName reads an identifier that may be a namespaced class name like
+// \App\User, keeping the separators in the token value.
+func (t *tokenizer) qualifiedName() (Token, error) {
+	start := t.loc
+	var name string
+	end := t.loc
+	for {
+		if t.char() == '\\' {
+			separator := t.loc
+			name += "\\"
+			t.next()
+			if !isIdentifierFirstChar(t.char()) {
+				return Token{}, fmt.Errorf("expected a name after \\ at %s", separator)
+			}
+		}
+		segment := t.identifier()
+		name += segment.Val
+		end = segment.Loc.End
+		if t.char() != '\\' {
+			break
+		}
+	}
+	return Token{Kind: Identifier, Val: name, Loc: NewSpan(start, end)}, nil
+}
+
+func (t *tokenizer) ellipsis() (Token, error) {
+	start := t.loc
+	end := t.loc
+	for i := 0; i < 3; i++ {
+		if t.char() != '.' {
+			return Token{}, fmt.Errorf("unexpected character . at %s", start)
+		}
+		end = t.loc
+		t.next()
+	}
+	return NewSymbolToken(Ellipsis, NewSpan(start, end)), nil
+}
+
+func (t *tokenizer) variable() (Token, error) {
+	start := t.loc
+	t.next()
+	if !isIdentifierFirstChar(t.char()) {
+		return Token{}, fmt.Errorf("expected a variable name after $ at %s", start)
+	}
+	name := t.identifier()
+	return Token{Kind: Variable, Val: name.Val, Loc: NewSpan(start, name.Loc.End)}, nil
+}
+
 func isIdentifierFirstChar(char rune) bool {
-	return char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+	return char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char == '_'
 }
 
 func isIdentifierChar(char rune) bool {
-	return isIdentifierFirstChar(char) || char == '-' || char == '*' || char == '_'
+	return isIdentifierFirstChar(char) || char >= '0' && char <= '9' || char == '-' || char == '*' || char == '_'
+}
+
+func isIdentifier(s string) bool {
+	for i, char := range s {
+		if i == 0 && !isIdentifierFirstChar(char) || !isIdentifierChar(char) {
+			return false
+		}
+	}
+	return s != ""
 }
 
 func isWhitespace(char rune) bool {
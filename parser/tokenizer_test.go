@@ -26,6 +26,12 @@ func TestTokenize(t *testing.T) {
 			parser.NewSymbolToken(parser.Gt, parser.NewSingleCharSpan(1, 21)),
 		}},
 		{"array-key", []parser.Token{parser.NewIdentifierToken("array-key", parser.NewSpanFromInts(1, 1, 1, 9))}},
+		{"\\App\\User", []parser.Token{parser.NewIdentifierToken("\\App\\User", parser.NewSpanFromInts(1, 1, 1, 9))}},
+		{"Entity\\User|int", []parser.Token{
+			parser.NewIdentifierToken("Entity\\User", parser.NewSpanFromInts(1, 1, 1, 11)),
+			parser.NewSymbolToken(parser.Pipe, parser.NewSingleCharSpan(1, 12)),
+			parser.NewIdentifierToken("int", parser.NewSpanFromInts(1, 13, 1, 15)),
+		}},
 		{"array{}", []parser.Token{
 			parser.NewIdentifierToken("array", parser.NewSpanFromInts(1, 1, 1, 5)),
 			parser.NewSymbolToken(parser.Lbrace, parser.NewSingleCharSpan(1, 6)),
@@ -105,6 +111,30 @@ func TestTokenize(t *testing.T) {
 			parser.NewSymbolToken(parser.Comma, parser.NewSingleCharSpan(2, 16)),
 			parser.NewSymbolToken(parser.Rbrace, parser.NewSingleCharSpan(3, 1)),
 		}},
+		{"foo?: int", []parser.Token{
+			parser.NewIdentifierToken("foo", parser.NewSpanFromInts(1, 1, 1, 3)),
+			parser.NewSymbolToken(parser.Question, parser.NewSingleCharSpan(1, 4)),
+			parser.NewSymbolToken(parser.Colon, parser.NewSingleCharSpan(1, 5)),
+			parser.NewIdentifierToken("int", parser.NewSpanFromInts(1, 7, 1, 9)),
+		}},
+		{"int64 key_1", []parser.Token{
+			parser.NewIdentifierToken("int64", parser.NewSpanFromInts(1, 1, 1, 5)),
+			parser.NewIdentifierToken("key_1", parser.NewSpanFromInts(1, 7, 1, 11)),
+		}},
+		{"int ...$rest", []parser.Token{
+			parser.NewIdentifierToken("int", parser.NewSpanFromInts(1, 1, 1, 3)),
+			parser.NewSymbolToken(parser.Ellipsis, parser.NewSpanFromInts(1, 5, 1, 7)),
+			parser.NewVariableToken("rest", parser.NewSpanFromInts(1, 8, 1, 12)),
+		}},
+		{"$foo_bar", []parser.Token{
+			parser.NewVariableToken("foo_bar", parser.NewSpanFromInts(1, 1, 1, 8)),
+		}},
+		{"T['a']", []parser.Token{
+			parser.NewIdentifierToken("T", parser.NewSpanFromInts(1, 1, 1, 1)),
+			parser.NewSymbolToken(parser.Lbracket, parser.NewSingleCharSpan(1, 2)),
+			parser.NewStringLiteralToken("a", parser.NewSpanFromInts(1, 3, 1, 5)),
+			parser.NewSymbolToken(parser.Rbracket, parser.NewSingleCharSpan(1, 6)),
+		}},
 		{"string | \"foo", []parser.Token{
 			parser.NewIdentifierToken("string", parser.NewSpanFromInts(1, 1, 1, 6)),
 			parser.NewSymbolToken(parser.Pipe, parser.NewSingleCharSpan(1, 8)),
@@ -0,0 +1,269 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind uint8
+
+const (
+	name tokenKind = iota
+	variable
+	stringLiteral
+	number
+	symbol
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	line int
+}
+
+func (t token) is(kind tokenKind, val string) bool {
+	return t.kind == kind && strings.EqualFold(t.val, val)
+}
+
+func (t token) isSymbol(val string) bool {
+	return t.kind == symbol && t.val == val
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.is(name, keyword)
+}
+
+// lexer splits PHP source into the tokens the scanner cares about. Inline
+// HTML, whitespace and comments are dropped, and names keep their namespace
+// separators, so Foo\Bar is a single token.
+type lexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+var symbols = []string{"?->", "...", "::", "=>", "->", "#["}
+
+func tokenize(src string) ([]token, error) {
+	l := &lexer{src: src, line: 1}
+	var tokens []token
+	l.skipHTML()
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "?>"):
+			l.pos += 2
+			tokens = append(tokens, token{kind: symbol, val: ";", line: l.line})
+			l.skipHTML()
+		case strings.HasPrefix(l.src[l.pos:], "//") || c == '#' && !strings.HasPrefix(l.src[l.pos:], "#["):
+			l.skipLineComment()
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			if err := l.skipBlockComment(); err != nil {
+				return nil, err
+			}
+		case c == '\'' || c == '"' || c == '`':
+			t, err := l.quoted(c)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+		case strings.HasPrefix(l.src[l.pos:], "<<<"):
+			t, err := l.heredoc()
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+		case c == '$' && l.pos+1 < len(l.src) && isNameStart(l.src[l.pos+1]):
+			start := l.pos
+			l.pos++
+			l.skipName(false)
+			tokens = append(tokens, token{kind: variable, val: l.src[start+1 : l.pos], line: l.line})
+		case isNameStart(c) || c == '\\':
+			start := l.pos
+			l.skipName(true)
+			tokens = append(tokens, token{kind: name, val: l.src[start:l.pos], line: l.line})
+		case isDigit(c) || c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1]):
+			tokens = append(tokens, l.number())
+		default:
+			tokens = append(tokens, l.symbol())
+		}
+	}
+	return tokens, nil
+}
+
+// skipHTML skips everything up to and including the next open tag.
+func (l *lexer) skipHTML() {
+	i := strings.Index(l.src[l.pos:], "<?")
+	if i < 0 {
+		l.advance(len(l.src) - l.pos)
+		return
+	}
+	l.advance(i + 2)
+	switch {
+	case strings.HasPrefix(strings.ToLower(l.src[l.pos:]), "php"):
+		l.pos += 3
+	case strings.HasPrefix(l.src[l.pos:], "="):
+		l.pos++
+	}
+}
+
+func (l *lexer) advance(n int) {
+	l.line += strings.Count(l.src[l.pos:l.pos+n], "\n")
+	l.pos += n
+}
+
+func (l *lexer) skipLineComment() {
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' && !strings.HasPrefix(l.src[l.pos:], "?>") {
+		l.pos++
+	}
+}
+
+func (l *lexer) skipBlockComment() error {
+	line := l.line
+	i := strings.Index(l.src[l.pos+2:], "*/")
+	if i < 0 {
+		return fmt.Errorf("unterminated comment at line %d", line)
+	}
+	l.advance(i + 4)
+	return nil
+}
+
+func (l *lexer) quoted(quote byte) (token, error) {
+	line := l.line
+	var b strings.Builder
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == quote {
+			l.pos++
+			return token{kind: stringLiteral, val: b.String(), line: line}, nil
+		}
+		if c == '\\' && l.pos+1 < len(l.src) {
+			b.WriteString(unescape(quote, l.src[l.pos+1]))
+			if l.src[l.pos+1] == '\n' {
+				l.line++
+			}
+			l.pos += 2
+			continue
+		}
+		if c == '\n' {
+			l.line++
+		}
+		b.WriteByte(c)
+		l.pos++
+	}
+	return token{}, fmt.Errorf("unterminated string at line %d", line)
+}
+
+func unescape(quote, c byte) string {
+	if quote == '\'' {
+		if c == '\'' || c == '\\' {
+			return string(c)
+		}
+		return "\\" + string(c)
+	}
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	case 'v':
+		return "\v"
+	case 'f':
+		return "\f"
+	case 'e':
+		return "\x1b"
+	case '\\', '$', quote:
+		return string(c)
+	}
+	return "\\" + string(c)
+}
+
+// heredoc reads a heredoc or nowdoc. The body is kept without interpolation,
+// but like in PHP 7.3 and later the indentation of the closing marker is
+// removed from every line.
+func (l *lexer) heredoc() (token, error) {
+	line := l.line
+	l.pos += 3
+	for l.pos < len(l.src) && (l.src[l.pos] == ' ' || l.src[l.pos] == '\t') {
+		l.pos++
+	}
+	rest := l.src[l.pos:]
+	end := strings.IndexByte(rest, '\n')
+	if end < 0 {
+		return token{}, fmt.Errorf("unterminated heredoc at line %d", line)
+	}
+	label := strings.Trim(strings.TrimSpace(rest[:end]), `'"`)
+	l.advance(end + 1)
+	start := l.pos
+	for l.pos < len(l.src) {
+		lineEnd := strings.IndexByte(l.src[l.pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(l.src) - l.pos
+		}
+		current := strings.TrimLeft(l.src[l.pos:l.pos+lineEnd], " \t")
+		if strings.HasPrefix(current, label) && (len(current) == len(label) || !isNameChar(current[len(label)])) {
+			indent := l.src[l.pos : l.pos+lineEnd-len(current)]
+			lines := strings.Split(strings.TrimSuffix(l.src[start:l.pos], "\n"), "\n")
+			for i, bodyLine := range lines {
+				lines[i] = strings.TrimPrefix(bodyLine, indent)
+			}
+			l.pos += lineEnd - len(current) + len(label)
+			return token{kind: stringLiteral, val: strings.Join(lines, "\n"), line: line}, nil
+		}
+		l.advance(min(lineEnd+1, len(l.src)-l.pos))
+	}
+	return token{}, fmt.Errorf("unterminated heredoc at line %d", line)
+}
+
+func (l *lexer) skipName(qualified bool) {
+	for l.pos < len(l.src) && (isNameChar(l.src[l.pos]) || qualified && l.src[l.pos] == '\\') {
+		l.pos++
+	}
+}
+
+func (l *lexer) number() token {
+	start := l.pos
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case isNameChar(c):
+		case c == '.' && !strings.HasPrefix(l.src[l.pos:], ".."):
+		case (c == '+' || c == '-') && (l.src[l.pos-1] == 'e' || l.src[l.pos-1] == 'E') && !strings.HasPrefix(strings.ToLower(l.src[start:]), "0x"):
+		default:
+			return token{kind: number, val: l.src[start:l.pos], line: l.line}
+		}
+		l.pos++
+	}
+	return token{kind: number, val: l.src[start:l.pos], line: l.line}
+}
+
+func (l *lexer) symbol() token {
+	for _, s := range symbols {
+		if strings.HasPrefix(l.src[l.pos:], s) {
+			l.pos += len(s)
+			return token{kind: symbol, val: s, line: l.line}
+		}
+	}
+	l.pos++
+	return token{kind: symbol, val: l.src[l.pos-1 : l.pos], line: l.line}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+// Register adds the declarations of files to env. Classes, interfaces and
+// enums go into the class registry, enums with their cases, and class
+// constants become available to constant fetches like Foo::BAR. Traits are
+// not types, so they are left out.
+func Register(env *types.Env, files ...*File) {
+	if env.Classes == nil {
+		env.Classes = types.NewClassRegistry()
+	}
+	for _, file := range files {
+		for _, d := range file.Declarations {
+			register(env, d)
+		}
+	}
+}
+
+func register(env *types.Env, d *Declaration) {
+	if d.Kind == Trait {
+		return
+	}
+	class := &types.Class{Name: d.Name, Interface: d.Kind == Interface}
+	switch {
+	case d.Kind == Interface:
+		class.Interfaces = names(d.Extends)
+	case len(d.Extends) > 0:
+		class.Parent = parser.NewSimpleNode(d.Extends[0])
+		fallthrough
+	default:
+		class.Interfaces = names(d.Implements)
+	}
+	env.Classes.Add(class)
+
+	if len(d.Constants) > 0 {
+		constants := make(map[string]parser.Node, len(d.Constants))
+		for _, c := range d.Constants {
+			if c.Value != nil {
+				constants[c.Name] = c.Value
+			}
+		}
+		if env.Constants == nil {
+			env.Constants = map[string]map[string]parser.Node{}
+		}
+		env.Constants[d.Name] = constants
+	}
+
+	if d.Kind == Enum {
+		cases := make([]*types.EnumCase, len(d.Cases))
+		for i, c := range d.Cases {
+			if c.Value != nil {
+				cases[i] = types.NewBackedEnumCase(c.Name, c.Value)
+			} else {
+				cases[i] = types.NewEnumCase(c.Name)
+			}
+		}
+		if env.Enums == nil {
+			env.Enums = map[string]*types.Enum{}
+		}
+		env.Enums[d.Name] = types.NewEnum(d.Name, cases...)
+	}
+}
+
+func names(classes []string) []parser.Node {
+	nodes := make([]parser.Node, len(classes))
+	for i, class := range classes {
+		nodes[i] = parser.NewSimpleNode(class)
+	}
+	return nodes
+}
@@ -0,0 +1,135 @@
+package scanner_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/scanner"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestRegister(t *testing.T) {
+	file := mustScan(t, `<?php
+		namespace App;
+		use Countable;
+		interface Entity {}
+		trait Timestamps {}
+		class User implements Entity, Countable { const ROLE = 'user'; }
+		class Admin extends User { use Timestamps; const ROLE = 'admin', LEVEL = 3; }
+		enum Status: string implements Entity { case Active = 'active'; case Inactive = 'inactive'; const DEFAULT = 'active'; }`)
+	env := &types.Env{}
+	scanner.Register(env, file)
+
+	tests := []struct {
+		sub   types.Type
+		super types.Type
+		want  bool
+	}{
+		{types.NewObjectType("App\\Admin"), types.NewObjectType("App\\User"), true},
+		{types.NewObjectType("app\\admin"), types.NewObjectType("\\Countable"), true},
+		{types.NewObjectType("App\\Admin"), types.NewObjectType("App\\Entity"), true},
+		{types.NewObjectType("App\\Status"), types.NewObjectType("App\\Entity"), true},
+		{types.NewObjectType("App\\User"), types.NewObjectType("App\\Admin"), false},
+		{types.NewObjectType("App\\Admin"), types.NewObjectType("App\\Timestamps"), false},
+	}
+	for _, test := range tests {
+		if got := env.IsSubtype(test.sub, test.super); got != test.want {
+			t.Errorf("IsSubtype(%s, %s) = %v, want %v", test.sub, test.super, got, test.want)
+		}
+	}
+	if env.Classes.Class("App\\Timestamps") != nil {
+		t.Error("traits must not be registered as classes")
+	}
+
+	constants := []struct {
+		class string
+		name  string
+		want  string
+	}{
+		{"App\\Admin", "*", "3 | \"admin\""},
+		{"App\\User", "ROLE", "\"user\""},
+		{"App\\Status", "*", "App\\Status::Active | App\\Status::Inactive | \"active\""},
+		{"App\\Status", "DEFAULT", "\"active\""},
+	}
+	for _, c := range constants {
+		got, err := env.Resolve(parser.NewConstFetchNode(c.class, c.name))
+		if err != nil {
+			t.Errorf("Resolve(%s::%s) returned error: %v", c.class, c.name, err)
+			continue
+		}
+		if got.String() != c.want {
+			t.Errorf("Resolve(%s::%s) = %s, want %s", c.class, c.name, got, c.want)
+		}
+	}
+
+	resolver := file.Declarations[0].Scope.NameResolver()
+	sub, err := parser.Parse("Admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	super, err := parser.Parse("User&\\Countable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subType, err := env.Resolve(resolver.Resolve(sub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	superType, err := env.Resolve(resolver.Resolve(super))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !env.IsSubtype(subType, superType) {
+		t.Errorf("IsSubtype(%s, %s) = false, want true", subType, superType)
+	}
+}
+
+func TestRegister_Heredoc(t *testing.T) {
+	file := mustScan(t, "<?php\nclass Text {\n"+
+		"    const C = <<<EOT\n    x\n    EOT;\n"+
+		"    const D = <<<'EOT'\n      a\n        b\n      EOT;\n"+
+		"}")
+	env := &types.Env{}
+	scanner.Register(env, file)
+	for name, want := range map[string]string{"C": "\"x\"", "D": "\"a\n  b\""} {
+		got, err := env.Resolve(parser.NewConstFetchNode("Text", name))
+		if err != nil {
+			t.Errorf("Resolve(Text::%s) returned error: %v", name, err)
+			continue
+		}
+		if got.String() != want {
+			t.Errorf("Resolve(Text::%s) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestRegister_ArrayConstants(t *testing.T) {
+	file := mustScan(t, `<?php
+		class Config {
+			const NAMES = ['first' => 'Ada', 'last' => 'Lovelace'];
+			const LEVELS = array('debug', 'info');
+		}`)
+	env := &types.Env{}
+	scanner.Register(env, file)
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"key-of<Config::NAMES>", "\"first\" | \"last\""},
+		{"value-of<Config::LEVELS>", "\"debug\" | \"info\""},
+	}
+	for _, test := range tests {
+		node, err := parser.Parse(test.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := env.Resolve(node)
+		if err != nil {
+			t.Errorf("Resolve(%s) returned error: %v", test.src, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,656 @@
+// Package scanner extracts class-like declarations from PHP source without a
+// PHP runtime, so that the types package can learn about the classes,
+// interfaces and enums a code base declares.
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+type Kind uint8
+
+const (
+	Class Kind = iota
+	Interface
+	Trait
+	Enum
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Interface:
+		return "interface"
+	case Trait:
+		return "trait"
+	case Enum:
+		return "enum"
+	}
+	return "class"
+}
+
+// File holds the declarations found in one PHP file.
+type File struct {
+	Path         string
+	Scopes       []*Scope
+	Declarations []*Declaration
+}
+
+// Scope is a namespace block of a file together with the names it imports.
+// All keys are lower case, as PHP names are case-insensitive.
+type Scope struct {
+	Namespace string
+	Uses      map[string]string
+	Functions map[string]string
+	Constants map[string]string
+	// resolver is built from Uses on first use and dropped whenever the
+	// scanner adds an import.
+	resolver *types.NameResolver
+}
+
+// Declaration is a class, interface, trait or enum. All names are fully
+// qualified, without a leading backslash.
+type Declaration struct {
+	Kind        Kind
+	Name        string
+	Extends     []string
+	Implements  []string
+	Traits      []string
+	BackingType string
+	Constants   []*Constant
+	Cases       []*Case
+	Scope       *Scope
+	Line        int
+}
+
+// Constant is a class constant. Value is nil if the initializer is not a
+// literal.
+type Constant struct {
+	Name  string
+	Value parser.Node
+}
+
+// Case is an enum case. Value is nil for cases of pure enums.
+type Case struct {
+	Name  string
+	Value parser.Node
+}
+
+func newScope(namespace string) *Scope {
+	return &Scope{
+		Namespace: namespace,
+		Uses:      map[string]string{},
+		Functions: map[string]string{},
+		Constants: map[string]string{},
+	}
+}
+
+// NameResolver returns a resolver for the class names used in this scope,
+// for example in the docblocks of its declarations. The resolver is shared by
+// all callers, so imports must not be added to it.
+func (s *Scope) NameResolver() *types.NameResolver {
+	if s.resolver == nil {
+		s.resolver = types.NewNameResolver(s.Namespace, s.Uses)
+	}
+	return s.resolver
+}
+
+// ResolveClass returns the fully qualified form of a class name as written in
+// this scope.
+func (s *Scope) ResolveClass(name string) string {
+	return s.NameResolver().ResolveClass(name)
+}
+
+func (s *Scope) qualify(name string) string {
+	if s.Namespace == "" {
+		return name
+	}
+	return s.Namespace + "\\" + name
+}
+
+// ScanDir scans all .php files below dir.
+func ScanDir(dir string) ([]*File, error) {
+	var files []*File
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".php" {
+			return err
+		}
+		file, err := ScanFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func ScanFile(path string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := Scan(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	file.Path = path
+	return file, nil
+}
+
+// Scan extracts the declarations from PHP source. Code outside of
+// declarations is skipped, so only syntax errors in the parts the scanner
+// reads are reported.
+func Scan(src string) (*File, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	s := &scanner{tokens: tokens, file: &File{}}
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+	return s.file, nil
+}
+
+type scanner struct {
+	tokens []token
+	pos    int
+	file   *File
+	scope  *Scope
+}
+
+func (s *scanner) peek() token {
+	return s.peekAt(0)
+}
+
+func (s *scanner) peekAt(offset int) token {
+	if s.pos+offset >= len(s.tokens) {
+		return token{kind: symbol, line: s.line()}
+	}
+	return s.tokens[s.pos+offset]
+}
+
+func (s *scanner) next() token {
+	t := s.peek()
+	s.pos++
+	return t
+}
+
+func (s *scanner) line() int {
+	if len(s.tokens) == 0 {
+		return 1
+	}
+	return s.tokens[min(s.pos, len(s.tokens)-1)].line
+}
+
+func (s *scanner) done() bool {
+	return s.pos >= len(s.tokens)
+}
+
+func (s *scanner) expectName(context string) (token, error) {
+	t := s.next()
+	if t.kind != name {
+		return t, fmt.Errorf("expected a name after %s at line %d", context, t.line)
+	}
+	return t, nil
+}
+
+func (s *scanner) enterScope(namespace string) {
+	s.scope = newScope(namespace)
+	s.file.Scopes = append(s.file.Scopes, s.scope)
+}
+
+func (s *scanner) scan() error {
+	s.enterScope("")
+	depth := 0
+	namespaceDepth := -1
+	for !s.done() {
+		previous := token{kind: symbol}
+		if s.pos > 0 {
+			previous = s.tokens[s.pos-1]
+		}
+		t := s.peek()
+		switch {
+		case t.isSymbol("{"):
+			depth++
+			s.pos++
+		case t.isSymbol("}"):
+			depth--
+			s.pos++
+			if depth == namespaceDepth {
+				namespaceDepth = -1
+				s.enterScope("")
+			}
+		case previous.isSymbol("::") || previous.isSymbol("->") || previous.isSymbol("?->"):
+			s.pos++
+		case t.isKeyword("namespace") && (s.peekAt(1).kind == name || s.peekAt(1).isSymbol("{")):
+			s.pos++
+			namespace := ""
+			if s.peek().kind == name {
+				namespace = strings.TrimPrefix(s.next().val, "\\")
+			}
+			s.enterScope(namespace)
+			if s.peek().isSymbol("{") {
+				namespaceDepth = depth
+			}
+		case t.isKeyword("use") && !s.peekAt(1).isSymbol("("):
+			s.pos++
+			if err := s.scanUse(); err != nil {
+				return err
+			}
+		case s.isDeclaration(previous):
+			if err := s.scanDeclaration(); err != nil {
+				return err
+			}
+		default:
+			s.pos++
+		}
+	}
+	return nil
+}
+
+var kinds = map[string]Kind{"class": Class, "interface": Interface, "trait": Trait, "enum": Enum}
+
+func (s *scanner) isDeclaration(previous token) bool {
+	t := s.peek()
+	kind, ok := kinds[strings.ToLower(t.val)]
+	if t.kind != name || !ok || previous.isKeyword("new") || s.peekAt(1).kind != name {
+		return false
+	}
+	if kind != Enum {
+		return true
+	}
+	after := s.peekAt(2)
+	return after.isSymbol("{") || after.isSymbol(":") || after.isKeyword("implements")
+}
+
+// scanUse reads the rest of a use statement, including group uses like
+// use App\{User, Admin as Root}.
+func (s *scanner) scanUse() error {
+	imports := s.scope.Uses
+	switch {
+	case s.peek().isKeyword("function"):
+		imports = s.scope.Functions
+		s.pos++
+	case s.peek().isKeyword("const"):
+		imports = s.scope.Constants
+		s.pos++
+	}
+	for {
+		t, err := s.expectName("use")
+		if err != nil {
+			return err
+		}
+		prefix := strings.TrimPrefix(t.val, "\\")
+		if s.peek().isSymbol("{") {
+			s.pos++
+			if err := s.scanUseGroup(imports, prefix); err != nil {
+				return err
+			}
+		} else if err := s.scanUseClause(imports, prefix); err != nil {
+			return err
+		}
+		switch t := s.next(); {
+		case t.isSymbol(","):
+		case t.isSymbol(";"):
+			return nil
+		default:
+			return fmt.Errorf("unexpected %q in use statement at line %d", t.val, t.line)
+		}
+	}
+}
+
+func (s *scanner) scanUseGroup(imports map[string]string, prefix string) error {
+	for {
+		if s.peek().isSymbol("}") {
+			s.pos++
+			return nil
+		}
+		target := imports
+		switch {
+		case s.peek().isKeyword("function") && s.peekAt(1).kind == name:
+			target = s.scope.Functions
+			s.pos++
+		case s.peek().isKeyword("const") && s.peekAt(1).kind == name:
+			target = s.scope.Constants
+			s.pos++
+		}
+		t, err := s.expectName("use")
+		if err != nil {
+			return err
+		}
+		if err := s.scanUseClause(target, prefix+t.val); err != nil {
+			return err
+		}
+		switch t := s.next(); {
+		case t.isSymbol(","):
+		case t.isSymbol("}"):
+			return nil
+		default:
+			return fmt.Errorf("unexpected %q in group use at line %d", t.val, t.line)
+		}
+	}
+}
+
+func (s *scanner) scanUseClause(imports map[string]string, name string) error {
+	alias := name[strings.LastIndex(name, "\\")+1:]
+	if s.peek().isKeyword("as") {
+		s.pos++
+		t, err := s.expectName("as")
+		if err != nil {
+			return err
+		}
+		alias = t.val
+	}
+	imports[strings.ToLower(alias)] = name
+	s.scope.resolver = nil
+	return nil
+}
+
+func (s *scanner) scanDeclaration() error {
+	keyword := s.next()
+	n, err := s.expectName(keyword.val)
+	if err != nil {
+		return err
+	}
+	d := &Declaration{Kind: kinds[strings.ToLower(keyword.val)], Name: s.scope.qualify(n.val), Scope: s.scope, Line: keyword.line}
+	for !s.peek().isSymbol("{") {
+		t := s.next()
+		switch {
+		case s.done() && t.val == "":
+			return fmt.Errorf("unexpected end of file in %s %s", d.Kind, d.Name)
+		case t.isKeyword("extends"):
+			if d.Extends, err = s.scanNames("extends"); err != nil {
+				return err
+			}
+		case t.isKeyword("implements"):
+			if d.Implements, err = s.scanNames("implements"); err != nil {
+				return err
+			}
+		case t.isSymbol(":") && d.Kind == Enum:
+			backing, err := s.expectName(":")
+			if err != nil {
+				return err
+			}
+			d.BackingType = strings.ToLower(backing.val)
+		default:
+			return fmt.Errorf("unexpected %q in declaration of %s at line %d", t.val, d.Name, t.line)
+		}
+	}
+	s.pos++
+	if err := s.scanBody(d); err != nil {
+		return err
+	}
+	s.file.Declarations = append(s.file.Declarations, d)
+	return nil
+}
+
+func (s *scanner) scanNames(context string) ([]string, error) {
+	var names []string
+	for {
+		t, err := s.expectName(context)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, s.scope.ResolveClass(t.val))
+		if !s.peek().isSymbol(",") {
+			return names, nil
+		}
+		s.pos++
+	}
+}
+
+// scanBody reads the members of a declaration up to its closing brace. Only
+// constants, enum cases and trait uses are extracted, everything else,
+// including method bodies, is skipped.
+func (s *scanner) scanBody(d *Declaration) error {
+	depth := 0
+	for {
+		if s.done() {
+			return fmt.Errorf("unexpected end of file in %s %s", d.Kind, d.Name)
+		}
+		t := s.next()
+		switch {
+		case t.isSymbol("{"):
+			depth++
+		case t.isSymbol("}"):
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		case depth > 0:
+		case t.isKeyword("const"):
+			if err := s.scanConstants(d); err != nil {
+				return err
+			}
+		case t.isKeyword("case") && d.Kind == Enum:
+			if err := s.scanCase(d); err != nil {
+				return err
+			}
+		case t.isKeyword("use"):
+			traits, err := s.scanNames("use")
+			if err != nil {
+				return err
+			}
+			d.Traits = append(d.Traits, traits...)
+		}
+	}
+}
+
+func (s *scanner) scanConstants(d *Declaration) error {
+	for {
+		var names []token
+		for !s.peek().isSymbol("=") {
+			t := s.next()
+			if t.kind != name {
+				return fmt.Errorf("expected a constant name in %s at line %d", d.Name, t.line)
+			}
+			names = append(names, t)
+		}
+		s.pos++
+		if len(names) == 0 {
+			return fmt.Errorf("expected a constant name in %s at line %d", d.Name, s.line())
+		}
+		value, end := s.scanExpression()
+		d.Constants = append(d.Constants, &Constant{Name: names[len(names)-1].val, Value: value})
+		if end.isSymbol(";") {
+			return nil
+		}
+		if !end.isSymbol(",") {
+			return fmt.Errorf("unexpected end of file in %s %s", d.Kind, d.Name)
+		}
+	}
+}
+
+func (s *scanner) scanCase(d *Declaration) error {
+	n, err := s.expectName("case")
+	if err != nil {
+		return err
+	}
+	c := &Case{Name: n.val}
+	switch t := s.next(); {
+	case t.isSymbol("="):
+		value, end := s.scanExpression()
+		if !end.isSymbol(";") {
+			return fmt.Errorf("expected ; after case %s of %s at line %d", c.Name, d.Name, end.line)
+		}
+		c.Value = value
+	case !t.isSymbol(";"):
+		return fmt.Errorf("expected ; after case %s of %s at line %d", c.Name, d.Name, t.line)
+	}
+	d.Cases = append(d.Cases, c)
+	return nil
+}
+
+// scanExpression skips an initializer up to the , or ; that ends it and
+// returns it as a type if it is a literal, along with the token that ended it.
+// An unbalanced closing bracket ends it as well, but is left to the caller.
+func (s *scanner) scanExpression() (parser.Node, token) {
+	var tokens []token
+	nesting := 0
+	for !s.done() {
+		t := s.next()
+		switch {
+		case nesting == 0 && (t.isSymbol(",") || t.isSymbol(";")):
+			return literal(tokens), t
+		case t.isSymbol("(") || t.isSymbol("[") || t.isSymbol("{") || t.isSymbol("#["):
+			nesting++
+		case t.isSymbol(")") || t.isSymbol("]") || t.isSymbol("}"):
+			if nesting == 0 {
+				s.pos--
+				return literal(tokens), t
+			}
+			nesting--
+		}
+		tokens = append(tokens, t)
+	}
+	return nil, token{kind: symbol}
+}
+
+func literal(tokens []token) parser.Node {
+	negative := false
+	if len(tokens) == 2 && tokens[0].isSymbol("-") {
+		negative, tokens = true, tokens[1:]
+	}
+	if len(tokens) > 0 && tokens[0].isSymbol("[") {
+		return arrayLiteral(tokens[1:], "]")
+	}
+	if len(tokens) > 1 && tokens[0].isKeyword("array") && tokens[1].isSymbol("(") {
+		return arrayLiteral(tokens[2:], ")")
+	}
+	if len(tokens) != 1 {
+		return nil
+	}
+	t := tokens[0]
+	switch t.kind {
+	case stringLiteral:
+		if !negative {
+			return parser.NewStringLiteralNode(t.val)
+		}
+	case number:
+		value, err := strconv.ParseInt(strings.ReplaceAll(t.val, "_", ""), 0, 0)
+		if err != nil {
+			return parser.NewSimpleNode("float")
+		}
+		if negative {
+			value = -value
+		}
+		return parser.NewIntLiteralNode(int(value))
+	case name:
+		switch keyword := strings.ToLower(t.val); keyword {
+		case "true", "false", "null":
+			if !negative {
+				return parser.NewSimpleNode(keyword)
+			}
+		}
+	}
+	return nil
+}
+
+// arrayLiteral turns the tokens of an array initializer after its opening
+// bracket into a shape of its elements. Initializers that are not made of
+// literals only, like [...$a] or [1] + [2], are a plain array.
+func arrayLiteral(tokens []token, closing string) parser.Node {
+	if len(tokens) == 0 || !tokens[len(tokens)-1].isSymbol(closing) {
+		return parser.NewSimpleNode("array")
+	}
+	elements, ok := splitElements(tokens[:len(tokens)-1])
+	if !ok {
+		return parser.NewSimpleNode("array")
+	}
+	var values []parser.Node
+	var members []*parser.MemberNode
+	keyed := false
+	next := 0
+	for _, element := range elements {
+		key := strconv.Itoa(next)
+		if arrow := indexSymbol(element, "=>"); arrow >= 0 {
+			switch k := literal(element[:arrow]).(type) {
+			case *parser.StringLiteralNode:
+				key = k.Value
+			case *parser.IntLiteralNode:
+				key = strconv.Itoa(k.Value)
+			default:
+				return parser.NewSimpleNode("array")
+			}
+			element, keyed = element[arrow+1:], true
+		}
+		value := literal(element)
+		if value == nil {
+			return parser.NewSimpleNode("array")
+		}
+		member := parser.NewMember(key, value)
+		if i, err := strconv.Atoi(key); err == nil && member.KeyKind == parser.IntKey && i >= next {
+			next = i + 1
+		}
+		members = setMember(members, member)
+		values = append(values, value)
+	}
+	if !keyed {
+		return parser.NewCurlyListNode("array", values)
+	}
+	return parser.NewCurlyKeyValueNode("array", members)
+}
+
+// splitElements splits tokens at the commas that are not nested in brackets.
+// A trailing comma does not start another element. ok is false if the
+// brackets are not balanced, so the tokens are not a single initializer.
+func splitElements(tokens []token) (elements [][]token, ok bool) {
+	nesting, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.isSymbol("(") || t.isSymbol("[") || t.isSymbol("{"):
+			nesting++
+		case t.isSymbol(")") || t.isSymbol("]") || t.isSymbol("}"):
+			nesting--
+			if nesting < 0 {
+				return nil, false
+			}
+		case nesting == 0 && t.isSymbol(","):
+			elements = append(elements, tokens[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		elements = append(elements, tokens[start:])
+	}
+	return elements, nesting == 0
+}
+
+func indexSymbol(tokens []token, symbol string) int {
+	nesting := 0
+	for i, t := range tokens {
+		switch {
+		case t.isSymbol("(") || t.isSymbol("[") || t.isSymbol("{"):
+			nesting++
+		case t.isSymbol(")") || t.isSymbol("]") || t.isSymbol("}"):
+			nesting--
+		case nesting == 0 && t.isSymbol(symbol):
+			return i
+		}
+	}
+	return -1
+}
+
+// setMember adds member to members, replacing an earlier member with the
+// same key like a repeated key in an array initializer does.
+func setMember(members []*parser.MemberNode, member *parser.MemberNode) []*parser.MemberNode {
+	for i, m := range members {
+		if m.Key == member.Key {
+			members[i] = member
+			return members
+		}
+	}
+	return append(members, member)
+}
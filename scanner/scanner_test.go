@@ -0,0 +1,244 @@
+package scanner_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/scanner"
+)
+
+func mustScan(t *testing.T, src string) *scanner.File {
+	t.Helper()
+	file, err := scanner.Scan(src)
+	if err != nil {
+		t.Fatalf("Scan() returned error: %v", err)
+	}
+	return file
+}
+
+// summary renders the declarations of a file in a compact form, e.g.
+// "class App\Admin extends App\User implements Countable".
+func summary(file *scanner.File) string {
+	lines := make([]string, len(file.Declarations))
+	for i, d := range file.Declarations {
+		line := d.Kind.String() + " " + d.Name
+		if d.BackingType != "" {
+			line += ": " + d.BackingType
+		}
+		if len(d.Extends) > 0 {
+			line += " extends " + strings.Join(d.Extends, ", ")
+		}
+		if len(d.Implements) > 0 {
+			line += " implements " + strings.Join(d.Implements, ", ")
+		}
+		if len(d.Traits) > 0 {
+			line += " uses " + strings.Join(d.Traits, ", ")
+		}
+		for _, c := range d.Constants {
+			line += " const " + c.Name + "=" + nodeString(c.Value)
+		}
+		for _, c := range d.Cases {
+			line += " case " + c.Name + "=" + nodeString(c.Value)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func nodeString(n parser.Node) string {
+	if n == nil {
+		return "?"
+	}
+	return n.String()
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			"namespace and uses",
+			`<?php
+			namespace App\Model;
+
+			use App\Contract\Entity;
+			use Countable as C, App\Base;
+
+			final class Admin extends User implements Entity, C, \Stringable {}
+			abstract class User extends Base\Model {}`,
+			"class App\\Model\\Admin extends App\\Model\\User implements App\\Contract\\Entity, Countable, Stringable\n" +
+				"class App\\Model\\User extends App\\Base\\Model",
+		},
+		{
+			"group uses",
+			`<?php
+			namespace App;
+			use Lib\{Foo, Sub\Bar as Baz, function helper, const VERSION};
+			class A implements Foo, Baz, namespace\Local {}`,
+			"class App\\A implements Lib\\Foo, Lib\\Sub\\Bar, App\\Local",
+		},
+		{
+			"braced namespaces",
+			`<?php
+			namespace First { interface I {} }
+			namespace Second { use First\I; class C implements I {} }
+			namespace { class G {} }`,
+			"interface First\\I\nclass Second\\C implements First\\I\nclass G",
+		},
+		{
+			"interfaces, traits and enums",
+			`<?php
+			interface Shape extends Countable, JsonSerializable {}
+			trait Named { use Other; }
+			enum Suit { case Hearts; case Spades; }
+			enum Status: string implements HasLabel {
+				use Named;
+				case Active = 'active';
+				case Inactive = "in" ;
+				const DEFAULT = self::Active;
+				public function label(): string {
+					switch ($this) { case self::Active: return 'yes'; }
+					return 'no';
+				}
+			}`,
+			"interface Shape extends Countable, JsonSerializable\n" +
+				"trait Named uses Other\n" +
+				"enum Suit case Hearts=? case Spades=?\n" +
+				"enum Status: string implements HasLabel uses Named const DEFAULT=? case Active=\"active\" case Inactive=\"in\"",
+		},
+		{
+			"constants",
+			`<?php
+			class Config {
+				const A = 1, B = -2;
+				public const string NAME = 'config';
+				private const HEX = 0x1F, FLOAT = 1.5, ON = true, NONE = null;
+				const LIST = [1, 2], SUM = 1 + 2;
+				final public const OLD = array('x' => 1);
+				const NESTED = ['a b' => [true], 3 => 'x', 'y',], EMPTY = [];
+				const SPREAD = [...self::LIST], DYNAMIC = [self::A => 1], JOINED = [1] + [2];
+			}`,
+			"class Config const A=1 const B=-2 const NAME=\"config\" const HEX=31 const FLOAT=float const ON=true const NONE=null const LIST=array{1, 2} const SUM=? const OLD=array{x: 1}" +
+				" const NESTED=array{\"a b\": array{true}, 3: \"x\", 4: \"y\"} const EMPTY=array{} const SPREAD=array const DYNAMIC=array const JOINED=array",
+		},
+		{
+			"skipped code",
+			`<html><?php
+			// class Comment {}
+			# class Hash {}
+			/* class Block {} */
+			$x = "class InString {}";
+			$y = <<<EOT
+			class InHeredoc {}
+			EOT;
+			$name = Foo::class;
+			$obj = new class extends Base {};
+			$f = function () use ($x) { return $x; };
+			if (true) { #[Attribute] class Conditional {} }
+			?>
+			<p>class Html {}</p>
+			<?php class Last {}`,
+			"class Conditional\nclass Last",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := summary(mustScan(t, test.src)); got != test.want {
+				t.Errorf("Scan() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScan_Errors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"<?php\n$x = 'open", "unterminated string at line 2"},
+		{"<?php\n\n/* open", "unterminated comment at line 3"},
+		{"<?php class Foo {", "unexpected end of file in class Foo"},
+		{"<?php class Foo extends {}", "expected a name after extends at line 1"},
+		{"<?php enum E { case A = 1 }", "expected ; after case A of E at line 1"},
+		{"<?php use Foo Bar;", "unexpected \"Bar\" in use statement at line 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			_, err := scanner.Scan(test.src)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("Scan() error = %v, want %s", err, test.want)
+			}
+		})
+	}
+}
+
+func TestScope_ResolveClass(t *testing.T) {
+	file := mustScan(t, `<?php
+		namespace App;
+		use Lib\Collection;
+		use Lib\Util as U;`)
+	scope := file.Scopes[len(file.Scopes)-1]
+	tests := map[string]string{
+		"User":                  "App\\User",
+		"\\DateTime":            "DateTime",
+		"collection":            "Lib\\Collection",
+		"U\\Strings":            "Lib\\Util\\Strings",
+		"Model\\User":           "App\\Model\\User",
+		"namespace\\Model\\Foo": "App\\Model\\Foo",
+	}
+	for name, want := range tests {
+		if got := scope.ResolveClass(name); got != want {
+			t.Errorf("ResolveClass(%s) = %s, want %s", name, got, want)
+		}
+	}
+	if scope.NameResolver() != scope.NameResolver() {
+		t.Error("NameResolver() should be built once per scope")
+	}
+
+	file = mustScan(t, `<?php
+		namespace App;
+		use Lib\Base;
+		class A extends Base {}
+		use Lib\Other;
+		class B extends Other {}`)
+	if got := file.Declarations[1].Extends; len(got) != 1 || got[0] != "Lib\\Other" {
+		t.Errorf("B extends %v, want Lib\\Other", got)
+	}
+}
+
+func TestScanDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"User.php":          "<?php namespace App; class User {}",
+		"sub/Admin.php":     "<?php namespace App; class Admin extends User {}",
+		"sub/notes.txt":     "class Ignored {}",
+		"sub/deep/Role.php": "<?php namespace App; enum Role { case Admin; }",
+	}
+	for path, src := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scanned, err := scanner.ScanDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range scanned {
+		for _, d := range file.Declarations {
+			names = append(names, d.Name)
+		}
+	}
+	if got, want := strings.Join(names, ", "), "App\\User, App\\Admin, App\\Role"; got != want {
+		t.Errorf("ScanDir() declared %s, want %s", got, want)
+	}
+}
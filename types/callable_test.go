@@ -0,0 +1,71 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestResolve_GenericCallable(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"callable<T>(T $x): T", "callable<T>(T $x): T"},
+		{"callable<T of int>(T): list<T>", "callable<T of int>(T): list<T>"},
+		{"callable<T>(callable<T>(T): T): T", "callable<T>(callable<T>(T): T): T"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := mustResolve(t, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsSubtype_GenericCallable(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"callable<T>(T): T", "callable(int): int", true},
+		{"callable<T>(T): T", "callable(int): string", false},
+		{"callable<T>(T): T", "callable(int): int | string", true},
+		{"callable<T>(T): list<T>", "callable(string): list<string>", true},
+		{"callable<T>(T): list<T>", "callable(string): list<int>", false},
+		{"callable<T of int>(T): T", "callable(positive-int): int", true},
+		{"callable<T of int>(T): T", "callable(string): string", false},
+		{"callable<T>(T, T): void", "callable(int, string): void", true},
+		{"callable<T>(): T", "callable(): int", true},
+		{"callable<T of string>(): T", "callable(): int", false},
+		{"callable(int): int", "callable<T>(T): T", false},
+		{"callable(mixed): mixed", "callable<T>(T): mixed", true},
+		{"callable(int): void", "callable<T of int>(T): void", true},
+		{"callable(positive-int): void", "callable<T of int>(T): void", false},
+		{"callable<U>(U): U", "callable<T>(T): T", true},
+		{"callable<U>(U): int", "callable<T>(T): T", false},
+		{"callable<T>(T): T", "callable<T>(T): T", true},
+		{"callable<T of int>(T): T", "callable<T>(T): T", false},
+		{"callable<T>(T): T", "callable<T of int>(T): T", true},
+		{"callable<T>(T): T", "callable<T>(T): T | null", true},
+	}
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := mustResolve(t, test.sub), mustResolve(t, test.super)
+			if got := types.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExplain_GenericCallable(t *testing.T) {
+	reason := types.Explain(mustResolve(t, "callable<T of int>(T): T"), mustResolve(t, "callable(string): string"))
+	want := "callable<T of int>(T): T is not a subtype of callable(string): string\n" +
+		"  string does not satisfy the bound int of template T"
+	if reason == nil || reason.String() != want {
+		t.Errorf("Explain() =\n%v\nwant\n%s", reason, want)
+	}
+}
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+// Class is a declared class or interface. Parent is the extended class and
+// Interfaces are the implemented interfaces, or the extended ones if the
+// class is an interface itself. Both are written like in @extends and
+// @implements tags, so they bind the templates of the supertypes.
+type Class struct {
+	Name       string
+	Interface  bool
+	Templates  []*parser.TemplateParam
+	Parent     parser.Node
+	Interfaces []parser.Node
+	Properties []*Property
+}
+
+type Property struct {
+	Name string
+	Type parser.Node
+}
+
+func NewClass(name string, properties ...*Property) *Class {
+	return &Class{Name: name, Properties: properties}
+}
+
+func NewInterface(name string, parents ...parser.Node) *Class {
+	return &Class{Name: name, Interface: true, Interfaces: parents}
+}
+
+func NewProperty(name string, t parser.Node) *Property {
+	return &Property{Name: name, Type: t}
+}
+
+func (c *Class) Property(name string) *Property {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (c *Class) supertypes() []parser.Node {
+	if c.Parent == nil {
+		return c.Interfaces
+	}
+	return append([]parser.Node{c.Parent}, c.Interfaces...)
+}
+
+func (e *Env) class(name string) *Class {
+	return e.Classes.Class(name)
+}
+
+// bindings maps the templates of the class of t to the type arguments of t.
+// Templates without an argument fall back to their default or bound. An
+// argument that does not satisfy the bound of its template is an error.
+func (e *Env) bindings(t *ObjectType) (map[string]parser.Node, error) {
+	templates := e.templates(t.Class)
+	substitutions := make(map[string]parser.Node, len(t.TypeArguments))
+	for i, argument := range t.TypeArguments {
+		if i < len(templates) {
+			substitutions[templates[i].Name] = ToNode(argument)
+		}
+	}
+	bindings, err := e.bindTemplates(templates, substitutions)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t, err)
+	}
+	return bindings, nil
+}
+
+// ancestor returns t seen as an instance of class, with the type arguments
+// passed up through the @extends and @implements bindings on the way, or nil
+// if class is not a supertype of t. Type arguments of t that do not satisfy
+// their bounds and supertypes that do not resolve are an error.
+func (e *Env) ancestor(t *ObjectType, class string) (*ObjectType, error) {
+	return e.findAncestor(t, class, map[string]bool{})
+}
+
+func (e *Env) findAncestor(t *ObjectType, class string, seen map[string]bool) (*ObjectType, error) {
+	if sameClass(t.Class, class) {
+		return t, nil
+	}
+	c := e.class(t.Class)
+	if c == nil || seen[classKey(t.Class)] {
+		return nil, nil
+	}
+	seen[classKey(t.Class)] = true
+	bindings, err := e.bindings(t)
+	if err != nil {
+		return nil, err
+	}
+	for _, supertype := range c.supertypes() {
+		resolved, err := e.Resolve(parser.Substitute(supertype, bindings))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		if object, ok := resolved.(*ObjectType); ok {
+			ancestor, err := e.findAncestor(object, class, seen)
+			if ancestor != nil || err != nil {
+				return ancestor, err
+			}
+		}
+	}
+	return nil, nil
+}
+
+// properties returns the declared properties of t and its parent classes as
+// shape members, with the templates of the classes bound to the type
+// arguments on the way.
+func (e *Env) properties(t *ObjectType) ([]*ShapeMember, error) {
+	class := e.class(t.Class)
+	if class == nil {
+		return nil, fmt.Errorf("%s is not a known class", t.Class)
+	}
+	var members []*ShapeMember
+	seen := map[string]bool{}
+	for class != nil && !seen[classKey(class.Name)] {
+		seen[classKey(class.Name)] = true
+		bindings, err := e.bindings(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, property := range class.Properties {
+			if findMember(members, property.Name) != nil {
+				continue
+			}
+			value, err := e.Resolve(parser.Substitute(property.Type, bindings))
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, &ShapeMember{Key: property.Name, Value: value})
+		}
+		if class.Parent == nil {
+			break
+		}
+		parent, err := e.Resolve(parser.Substitute(class.Parent, bindings))
+		if err != nil {
+			return nil, err
+		}
+		object, ok := parent.(*ObjectType)
+		if !ok {
+			return nil, fmt.Errorf("%s cannot extend %s", class.Name, parent)
+		}
+		t, class = object, e.class(object.Class)
+	}
+	return members, nil
+}
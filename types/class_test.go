@@ -0,0 +1,223 @@
+package types_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestEnv_IsSubtype_ClassToObjectShape(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"Point", "object{x: int}", true},
+		{"Point", "object{x: int, y: int}", true},
+		{"point", "object{x: int}", true},
+		{"Point", "object{x: string}", false},
+		{"Point", "object{x: int, z: int}", false},
+		{"Point", "object{x: int, z?: int}", true},
+		{"Point", "object{x: int|string}", true},
+		{"Point", "object{x: positive-int}", false},
+		{"Empty", "object{}", true},
+		{"Box<int>", "object{value: int}", true},
+		{"Box<positive-int>", "object{value: int}", true},
+		{"Box<string>", "object{value: int}", false},
+		{"Unknown", "object{}", false},
+		{"object", "object{}", false},
+		{"object{x: int, y: int}", "Point", false},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := envResolve(t, env, test.sub), envResolve(t, env, test.super)
+			if got := env.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_Explain_ClassToObjectShape(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  string
+	}{
+		{"Point", "object{x: string}", "Point is not a subtype of object{x: string}\n  property x has an incompatible type\n    int is not a subtype of string"},
+		{"Point", "object{z: int}", "Point is not a subtype of object{z: int}\n  property z is required in target but missing in source (at 1:8-1:13)"},
+		{"Unknown", "object{}", "Unknown is not a subtype of object{}\n  Unknown is not a known class"},
+		{"Kennel<int>", "object{}", "Kennel<int> is not a subtype of object{}\n  Kennel<int>: int does not satisfy the bound Animal of template T"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			reason := env.Explain(envResolve(t, env, test.sub), envResolve(t, env, test.super))
+			if reason == nil || reason.String() != test.want {
+				t.Errorf("Explain(%s, %s) =\n%v\nwant\n%s", test.sub, test.super, reason, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_IsSubtype_ClassHierarchy(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"Admin", "User", true},
+		{"User", "Admin", false},
+		{"admin", "user", true},
+		{"Admin", "object{name: string}", true},
+		{"Collection<string>", "Countable", true},
+		{"Collection<string>", "Countable&Traversable", true},
+		{"Collection<string>", "Traversable<int, string>", true},
+		{"Collection<string>", "Traversable<int, int>", false},
+		{"Collection<positive-int>", "Traversable<int, int>", true},
+		{"Collection<positive-int>", "Collection<int>", false},
+		{"IntCollection", "Collection<int>", true},
+		{"IntCollection", "IteratorAggregate<int, int>", true},
+		{"IntCollection", "Collection<string>", false},
+		{"IntCollection", "object{first: int, sum: int}", true},
+		{"IntCollection", "object{first: string}", false},
+		{"Countable&Traversable<int, string>", "Countable", true},
+		{"User", "Countable", false},
+		{"Loop", "User", false},
+		{"Unknown", "User", false},
+		{"Kennel<Dog>", "object{all: list<Dog>}", true},
+		{"Kennel<Dog>", "object{all: list<Animal>}", true},
+		{"Kennel<Dog>", "object{all: list<int>}", false},
+		{"Kennel<int>", "object{all: list<int>}", false},
+		{"Status::Active", "HasLabel", true},
+		{"Status::Active", "Status", true},
+		{"Status", "HasLabel", true},
+		{"Suit::Hearts", "HasLabel", false},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := envResolve(t, env, test.sub), envResolve(t, env, test.super)
+			if got := env.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_Explain_ClassHierarchy(t *testing.T) {
+	env := testEnv(t)
+	reason := env.Explain(envResolve(t, env, "Joker"), envResolve(t, env, "Box<int>"))
+	want := "Joker is not a subtype of Box<int>\n  Joker: undefined enum case Suit::Joker"
+	if reason == nil || reason.String() != want {
+		t.Errorf("Explain(Joker, Box<int>) =\n%v\nwant\n%s", reason, want)
+	}
+}
+
+func TestEnv_Lattice_ClassHierarchy(t *testing.T) {
+	env := testEnv(t)
+	tests := []struct {
+		name string
+		got  func() types.Type
+		want string
+	}{
+		{"Simplify", func() types.Type { return env.Simplify(envResolve(t, env, "Admin|User")) }, "User"},
+		{"Join", func() types.Type { return env.Join(envResolve(t, env, "Admin"), envResolve(t, env, "User")) }, "User"},
+		{"Meet", func() types.Type { return env.Meet(envResolve(t, env, "Admin"), envResolve(t, env, "User")) }, "Admin"},
+		{"Remove", func() types.Type { return env.Remove(envResolve(t, env, "Admin|int"), envResolve(t, env, "User")) }, "int"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.got(); got.String() != test.want {
+				t.Errorf("%s = %s, want %s", test.name, got, test.want)
+			}
+		})
+	}
+	if got := types.Simplify(envResolve(t, env, "Admin|User")); got.String() != "Admin | User" {
+		t.Errorf("package-level Simplify = %s, want Admin | User", got)
+	}
+}
+
+func TestEnv_Resolve_OffsetAccess_ClassHierarchy(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"array{a: Admin, b: User}['a' | 'b']", "User"},
+		{"array{a: Admin, ...<string, User>}[string]", "User | null"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := envResolve(t, env, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestClassRegistry_IsSubclassOf(t *testing.T) {
+	registry := testEnv(t).Classes
+	tests := []struct {
+		class    string
+		ancestor string
+		want     bool
+	}{
+		{"IntCollection", "Traversable", true},
+		{"\\IntCollection", "countable", true},
+		{"Admin", "Admin", true},
+		{"User", "Admin", false},
+		{"Loop", "User", false},
+	}
+	for _, test := range tests {
+		if got := registry.IsSubclassOf(test.class, test.ancestor); got != test.want {
+			t.Errorf("IsSubclassOf(%s, %s) = %v, want %v", test.class, test.ancestor, got, test.want)
+		}
+	}
+}
+
+func TestLoadClassRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "classes.json")
+	src := `{"classes": [{"name": "Box", "templates": [{"name": "T", "bound": "object", "variance": "covariant"}], "properties": [{"name": "value", "type": "T"}]}]}`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := types.LoadClassRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box := registry.Class("box")
+	if box == nil {
+		t.Fatal("Class(box) = nil")
+	}
+	if len(box.Templates) != 1 || box.Templates[0].Variance != parser.Covariant || box.Templates[0].Bound.String() != "object" {
+		t.Errorf("Templates = %v", box.Templates)
+	}
+	if p := box.Property("value"); p == nil || p.Type.String() != "T" {
+		t.Errorf("Property(value) = %v", p)
+	}
+}
+
+func TestReadClassRegistry_Errors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`{"classes": [{"name": "A", "extends": ["B", "C"]}]}`, "class A can only extend one class"},
+		{`{"classes": [{"name": "A", "interface": true, "implements": ["B"]}]}`, "interface A cannot implement interfaces, use extends"},
+		{`{"classes": [{"name": "A", "templates": [{"name": "T", "variance": "sideways"}]}]}`, "A: unknown variance \"sideways\" of template T"},
+		{`{"classes": [{"name": "A", "properties": [{"name": "x", "type": "int|"}]}]}`, "A::$x: unexpected end of input, expected a type"},
+		{`{"classes": [{}]}`, "class without a name in class registry"},
+	}
+	for _, test := range tests {
+		_, err := types.ReadClassRegistry(strings.NewReader(test.src))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("ReadClassRegistry(%s) error = %v, want %s", test.src, err, test.want)
+		}
+	}
+}
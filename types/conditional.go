@@ -0,0 +1,72 @@
+package types
+
+import "github.com/MidnightDesign/php-types-go/parser"
+
+// resolveConditional resolves only the branch that the subject decides, so
+// that the other branch may be a type that only makes sense for the subjects
+// it applies to, like key-of<T> under (T is array ? ...). A subject that
+// cannot decide the branch yields the union of both.
+func (e *Env) resolveConditional(n *parser.ConditionalNode) (Type, error) {
+	ifNode, elseNode := n.If, n.Else
+	if n.Negated {
+		ifNode, elseNode = elseNode, ifNode
+	}
+	subject, ok, err := e.conditionalSubject(n.Subject)
+	if err != nil {
+		return nil, err
+	}
+	target, err := e.Resolve(n.Target)
+	if err != nil {
+		return nil, err
+	}
+	if ok && !e.mentionsCallableTemplate(subject) {
+		if e.IsSubtype(subject, target) {
+			return e.Resolve(ifNode)
+		}
+		if _, never := e.Meet(subject, target).(*NeverType); never {
+			return e.Resolve(elseNode)
+		}
+	}
+	ifType, err := e.Resolve(ifNode)
+	if err != nil {
+		return nil, err
+	}
+	elseType, err := e.Resolve(elseNode)
+	if err != nil {
+		return nil, err
+	}
+	return e.Simplify(union([]Type{ifType, elseType})), nil
+}
+
+// conditionalSubject resolves the subject of a conditional type. A parameter
+// without a known argument type cannot decide the branch, so ok is false.
+func (e *Env) conditionalSubject(node parser.Node) (Type, bool, error) {
+	if variable, ok := node.(*parser.VariableNode); ok {
+		argument, ok := e.Arguments[variable.Name]
+		return argument, ok, nil
+	}
+	subject, err := e.Resolve(node)
+	if err != nil {
+		return nil, false, err
+	}
+	return subject, true, nil
+}
+
+// mentionsCallableTemplate reports whether t refers to a template of the
+// generic callables being resolved, whose argument is not known yet.
+func (e *Env) mentionsCallableTemplate(t Type) bool {
+	switch t := t.(type) {
+	case *TemplateType:
+		return true
+	case *ObjectType:
+		if len(t.TypeArguments) == 0 && e.callableTemplates[t.Class] {
+			return true
+		}
+	}
+	found := false
+	mapChildren(t, func(child Type) Type {
+		found = found || e.mentionsCallableTemplate(child)
+		return child
+	})
+	return found
+}
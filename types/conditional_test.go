@@ -0,0 +1,67 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestEnv_Resolve_Conditional(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"(string is string ? int : bool)", "int"},
+		{"(\"foo\" is string ? int : bool)", "int"},
+		{"(int is string ? int : bool)", "bool"},
+		{"(int | string is string ? int : bool)", "int | bool"},
+		{"(int is not string ? int : bool)", "int"},
+		{"(string is not string ? int : bool)", "bool"},
+		{"(positive-int is int<0, max> ? list<int> : null)", "list<int>"},
+		{"(int is int ? (string is int ? 1 : 2) : 3)", "2"},
+		{"($flag is true ? string : null)", "string"},
+		{"($missing is true ? string : null)", "string | null"},
+		{"($value is not null ? array{a: int} : never)", "never"},
+		{"(int is array ? key-of<int> : null)", "null"},
+		{"(int is array ? int['a'] : null)", "null"},
+		{"(array{a: int} is array ? key-of<array{a: int}> : null)", "\"a\""},
+		{"callable<T>(T $x): (T is string ? int : bool)", "callable<T>(T $x): int | bool"},
+	}
+	env := &types.Env{Arguments: map[string]types.Type{
+		"flag":  types.NewBoolLiteralType(true),
+		"value": types.NewNullType(),
+	}}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := envResolve(t, env, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSubstitute_Conditional(t *testing.T) {
+	node, err := parser.Parse("(T is string ? int : bool)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := []*parser.TemplateParam{parser.NewTemplateParam("T", nil)}
+	for argument, want := range map[string]string{"\"foo\"": "int", "float": "bool", "scalar": "int | bool"} {
+		substitution, err := parser.Parse(argument)
+		if err != nil {
+			t.Fatal(err)
+		}
+		substituted, err := types.Substitute(node, params, map[string]parser.Node{"T": substitution})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resolved, err := types.Resolve(substituted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resolved.String(); got != want {
+			t.Errorf("Resolve(%s) = %s, want %s", substituted, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+type Enum struct {
+	Name  string
+	Cases []*EnumCase
+}
+
+type EnumCase struct {
+	Name  string
+	Value parser.Node
+}
+
+func NewEnum(name string, cases ...*EnumCase) *Enum {
+	return &Enum{Name: name, Cases: cases}
+}
+
+func NewEnumCase(name string) *EnumCase {
+	return &EnumCase{Name: name}
+}
+
+func NewBackedEnumCase(name string, value parser.Node) *EnumCase {
+	return &EnumCase{Name: name, Value: value}
+}
+
+func (e *Enum) IsBacked() bool {
+	return len(e.Cases) > 0 && e.Cases[0].Value != nil
+}
+
+func (e *Enum) Case(name string) *EnumCase {
+	for _, c := range e.Cases {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (e *Env) enum(name string) *Enum {
+	for class, enum := range e.Enums {
+		if sameClass(class, name) {
+			return enum
+		}
+	}
+	return nil
+}
+
+// ExpandEnum returns the union of all cases of t if t is an enum, so that
+// matches against the individual cases can be checked for exhaustiveness.
+func (e *Env) ExpandEnum(t Type) (Type, bool) {
+	object, ok := t.(*ObjectType)
+	if !ok || len(object.TypeArguments) > 0 {
+		return nil, false
+	}
+	enum := e.enum(object.Class)
+	if enum == nil {
+		return nil, false
+	}
+	cases := make([]Type, len(enum.Cases))
+	for i, c := range enum.Cases {
+		cases[i] = NewEnumCaseType(enum.Name, c.Name)
+	}
+	return union(cases), true
+}
+
+// resolveConstFetch resolves a reference to the cases and constants of an
+// enum or the constants of a class. A wildcard reference resolves to the
+// union of everything it matches.
+func (e *Env) resolveConstFetch(n *parser.ConstFetchNode) (Type, error) {
+	enum := e.enum(n.Class)
+	constants := e.classConstants(n.Class)
+	if enum == nil && constants == nil {
+		return nil, fmt.Errorf("cannot resolve %s, %s is not a known class or enum", n, n.Class)
+	}
+	var matches []Type
+	if enum != nil {
+		for _, c := range enum.Cases {
+			if matched, _ := path.Match(n.Name, c.Name); matched {
+				matches = append(matches, NewEnumCaseType(enum.Name, c.Name))
+			}
+		}
+		if !n.IsWildcard() && len(matches) > 0 {
+			return matches[0], nil
+		}
+	}
+	value, err := n.Expand(constants)
+	switch {
+	case err == nil:
+		resolved, err := e.Resolve(value)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, resolved)
+	case enum == nil:
+		return nil, err
+	}
+	if len(matches) == 0 {
+		if n.IsWildcard() {
+			return nil, fmt.Errorf("no enum cases match %s", n)
+		}
+		return nil, fmt.Errorf("undefined enum case %s", n)
+	}
+	return e.Simplify(union(matches)), nil
+}
@@ -0,0 +1,109 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestEnv_Resolve_Enum(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"Status::Active", "Status::Active"},
+		{"Status::*", "Status::Active | Status::Inactive | Status::Archived"},
+		{"Status::*ctive", "Status::Active | Status::Inactive"},
+		{"status::Active", "Status::Active"},
+		{"Status::DEFAULT", "Status::Active"},
+		{"Status::D*", "Status::Active"},
+		{"Status", "Status"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := envResolve(t, env, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_Resolve_EnumErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"Status::Deleted", "undefined enum case Status::Deleted"},
+		{"Status::X*", "no enum cases match Status::X*"},
+		{"Foo::BAR", "cannot resolve Foo::BAR, Foo is not a known class or enum"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			node, err := parser.Parse(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = env.Resolve(node)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("Resolve(%s) error = %v, want %s", test.src, err, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_IsSubtype_Enum(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"Status::Active", "Status", true},
+		{"Status::Active", "object", true},
+		{"Status::Active", "Status::Active", true},
+		{"Status::Active", "Status::Inactive", false},
+		{"Status::Active", "Suit", false},
+		{"Status", "Status::Active", false},
+		{"Status", "Status::*", true},
+		{"Status", "Status::Active | Status::Inactive", false},
+		{"Status", "Status::Active | Status::Inactive | Status::Archived | null", true},
+		{"Status | Suit", "Status::* | Suit::*", true},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := envResolve(t, env, test.sub), envResolve(t, env, test.super)
+			if got := env.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_ExpandEnum(t *testing.T) {
+	env := testEnv(t)
+	cases, ok := env.ExpandEnum(envResolve(t, env, "Suit"))
+	if !ok {
+		t.Fatal("expected Suit to be expanded")
+	}
+	if cases.String() != "Suit::Hearts | Suit::Spades" {
+		t.Errorf("ExpandEnum(Suit) = %s", cases)
+	}
+	if _, ok := env.ExpandEnum(types.NewObjectType("Foo")); ok {
+		t.Error("expected Foo not to be expanded")
+	}
+
+	remaining := types.Remove(cases, envResolve(t, env, "Suit::Hearts"))
+	if remaining.String() != "Suit::Spades" {
+		t.Errorf("expected Suit::Spades to remain unmatched, got %s", remaining)
+	}
+	remaining = types.Remove(remaining, envResolve(t, env, "Suit::Spades"))
+	if _, ok := remaining.(*types.NeverType); !ok {
+		t.Errorf("expected the match to be exhaustive, got %s remaining", remaining)
+	}
+	if met := types.Simplify(types.NewIntersectionType(envResolve(t, env, "Suit::Hearts"), envResolve(t, env, "Suit::Spades"))); met.String() != "never" {
+		t.Errorf("expected distinct cases to be disjoint, got %s", met)
+	}
+}
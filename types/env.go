@@ -0,0 +1,60 @@
+package types
+
+import "github.com/MidnightDesign/php-types-go/parser"
+
+// Env holds the declarations that the types being checked refer to. The zero
+// value knows about no declarations at all; it is what the package-level
+// functions like IsSubtype and Join use, so there classes are only related to
+// themselves.
+type Env struct {
+	Templates map[string][]*parser.TemplateParam
+	Enums     map[string]*Enum
+	Constants map[string]map[string]parser.Node
+	// Classes holds the declared classes and interfaces, which class types
+	// are checked against for inheritance and object shapes.
+	Classes *ClassRegistry
+	// Arguments holds the argument types that parameter conditionals like
+	// ($param is true ? A : B) are evaluated against.
+	Arguments map[string]Type
+	// callableTemplates holds the names of the templates of the generic
+	// callables being resolved, which conditionals cannot be decided on.
+	callableTemplates map[string]bool
+}
+
+func (e *Env) templates(class string) []*parser.TemplateParam {
+	for name, templates := range e.Templates {
+		if sameClass(name, class) {
+			return templates
+		}
+	}
+	if c := e.class(class); c != nil {
+		return c.Templates
+	}
+	return nil
+}
+
+// variance returns the variance of the i-th type argument of t: the
+// call-site variance if there is one, the declared variance otherwise.
+func (e *Env) variance(t *ObjectType, i int) parser.Variance {
+	if variance := t.variance(i); variance != parser.Invariant {
+		return variance
+	}
+	if templates := e.templates(t.Class); i < len(templates) {
+		return templates[i].Variance
+	}
+	return parser.Invariant
+}
+
+// withCallableTemplates returns a copy of e in which the given templates are
+// in scope.
+func (e *Env) withCallableTemplates(templates []*parser.TemplateParam) *Env {
+	scoped := *e
+	scoped.callableTemplates = make(map[string]bool, len(e.callableTemplates)+len(templates))
+	for name := range e.callableTemplates {
+		scoped.callableTemplates[name] = true
+	}
+	for _, template := range templates {
+		scoped.callableTemplates[template.Name] = true
+	}
+	return &scoped
+}
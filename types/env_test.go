@@ -0,0 +1,83 @@
+package types_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+const testClasses = `{"classes": [
+	{"name": "Point", "properties": [{"name": "x", "type": "int"}, {"name": "y", "type": "int"}]},
+	{"name": "Box", "templates": [{"name": "T"}], "properties": [{"name": "value", "type": "T"}]},
+	{"name": "Empty"},
+	{"name": "Countable", "interface": true},
+	{"name": "Traversable", "interface": true, "templates": [{"name": "K"}, {"name": "V", "variance": "covariant"}]},
+	{"name": "IteratorAggregate", "interface": true, "templates": [{"name": "K"}, {"name": "V", "variance": "covariant"}], "extends": ["Traversable<K, V>"]},
+	{"name": "Collection", "templates": [{"name": "T"}], "implements": ["IteratorAggregate<int, T>", "Countable"],
+	 "properties": [{"name": "first", "type": "T"}]},
+	{"name": "IntCollection", "extends": "Collection<int>", "properties": [{"name": "sum", "type": "int"}]},
+	{"name": "User", "properties": [{"name": "name", "type": "string"}]},
+	{"name": "Admin", "extends": "User"},
+	{"name": "Loop", "extends": "Loop"},
+	{"name": "Animal"},
+	{"name": "Dog", "extends": "Animal"},
+	{"name": "Kennel", "templates": [{"name": "T", "bound": "Animal"}, {"name": "U", "default": "list<T>"}],
+	 "properties": [{"name": "all", "type": "U"}]},
+	{"name": "HasLabel", "interface": true},
+	{"name": "Status", "implements": ["HasLabel"]},
+	{"name": "Suit"},
+	{"name": "Joker", "extends": "Box<Suit::Joker>"}
+]}`
+
+// testEnv returns the environment that the tests resolve class, enum and
+// constant names in.
+func testEnv(t *testing.T) *types.Env {
+	t.Helper()
+	registry, err := types.ReadClassRegistry(strings.NewReader(testClasses))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.Env{
+		Classes: registry,
+		Enums: map[string]*types.Enum{
+			"Status": types.NewEnum("Status",
+				types.NewBackedEnumCase("Active", parser.NewStringLiteralNode("active")),
+				types.NewBackedEnumCase("Inactive", parser.NewStringLiteralNode("inactive")),
+				types.NewBackedEnumCase("Archived", parser.NewStringLiteralNode("archived")),
+			),
+			"Suit": types.NewEnum("Suit", types.NewEnumCase("Hearts"), types.NewEnumCase("Spades")),
+		},
+		Constants: map[string]map[string]parser.Node{
+			"Status": {"DEFAULT": parser.NewConstFetchNode("Status", "Active")},
+			"Config": {
+				"DEFAULTS": parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+					parser.NewMember("host", parser.NewStringLiteralNode("localhost")),
+					parser.NewMember("port", parser.NewIntLiteralNode(80)),
+				}),
+				"LEVELS":        parser.NewCurlyListNode("array", []parser.Node{parser.NewStringLiteralNode("debug"), parser.NewStringLiteralNode("info")}),
+				"TIMEOUT":       parser.NewIntLiteralNode(30),
+				"RETRY_TIMEOUT": parser.NewIntLiteralNode(30),
+			},
+		},
+	}
+}
+
+func envResolve(t *testing.T, env *types.Env, src string) types.Type {
+	t.Helper()
+	node, err := parser.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned error: %v", src, err)
+	}
+	resolved, err := env.Resolve(node)
+	if err != nil {
+		t.Fatalf("Resolve(%q) returned error: %v", src, err)
+	}
+	return resolved
+}
+
+func mustResolve(t *testing.T, src string) types.Type {
+	t.Helper()
+	return envResolve(t, &types.Env{}, src)
+}
@@ -0,0 +1,300 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+type Conflict struct {
+	Template string
+	Message  string
+}
+
+func (c *Conflict) String() string {
+	if c.Template == "" {
+		return c.Message
+	}
+	return fmt.Sprintf("template %s: %s", c.Template, c.Message)
+}
+
+type InferenceError struct {
+	Conflicts []*Conflict
+}
+
+func (e *InferenceError) Error() string {
+	messages := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		messages[i] = conflict.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+type templateBounds struct {
+	lower []Type
+	upper []Type
+}
+
+type inference struct {
+	env       *Env
+	templates map[string]*templateBounds
+}
+
+// Infer computes the template parameters params of signature from the types
+// of the arguments it is called with. If the arguments contradict each other
+// or the signature, an *InferenceError listing the conflicts is returned.
+func Infer(params []*parser.TemplateParam, signature *parser.CallableNode, arguments []parser.Node) (map[string]parser.Node, error) {
+	inf := (&Env{}).newInference(params)
+	var conflicts []*Conflict
+	required := 0
+	for _, parameter := range signature.Parameters {
+		if !parameter.Optional && !parameter.Variadic {
+			required++
+		}
+	}
+	if len(arguments) < required {
+		conflicts = append(conflicts, &Conflict{Message: fmt.Sprintf("expected at least %d arguments, got %d", required, len(arguments))})
+	}
+	if len(arguments) > 0 && parameterAt(signature, len(arguments)-1) == nil {
+		conflicts = append(conflicts, &Conflict{Message: fmt.Sprintf("expected at most %d arguments, got %d", len(signature.Parameters), len(arguments))})
+	}
+	argumentTypes := make([]Type, len(arguments))
+	for i, argument := range arguments {
+		t, err := Resolve(argument)
+		if err != nil {
+			return nil, err
+		}
+		argumentTypes[i] = t
+		p := parameterAt(signature, i)
+		if p == nil {
+			continue
+		}
+		parameter, err := Resolve(p.Type)
+		if err != nil {
+			return nil, err
+		}
+		inf.unify(parameter, t, false)
+	}
+
+	substitutions := make(map[string]parser.Node, len(params))
+	for _, param := range params {
+		bounds := inf.templates[param.Name]
+		var inferred Type
+		switch {
+		case len(bounds.lower) > 0:
+			inferred = Simplify(&UnionType{Types: bounds.lower})
+			for _, upper := range bounds.upper {
+				if !IsSubtype(inferred, upper) {
+					conflicts = append(conflicts, &Conflict{
+						Template: param.Name,
+						Message:  fmt.Sprintf("inferred %s is not a subtype of %s", inferred, upper),
+					})
+				}
+			}
+		case len(bounds.upper) > 0:
+			inferred = bounds.upper[0]
+			for _, upper := range bounds.upper[1:] {
+				inferred = Meet(inferred, upper)
+			}
+		}
+		if inferred != nil {
+			substitutions[param.Name] = ToNode(inferred)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, &InferenceError{Conflicts: conflicts}
+	}
+
+	resolved, err := (&Env{}).bindTemplates(params, substitutions)
+	if err != nil {
+		return nil, &InferenceError{Conflicts: []*Conflict{{Message: err.Error()}}}
+	}
+	for i, argument := range argumentTypes {
+		p := parameterAt(signature, i)
+		if p == nil {
+			break
+		}
+		parameter, err := Resolve(parser.Substitute(p.Type, resolved))
+		if err != nil {
+			return nil, err
+		}
+		if !IsSubtype(argument, parameter) {
+			conflicts = append(conflicts, &Conflict{
+				Message: fmt.Sprintf("argument %d: %s is not a subtype of %s", i+1, argument, parameter),
+			})
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, &InferenceError{Conflicts: conflicts}
+	}
+	return substitutions, nil
+}
+
+// parameterAt returns the parameter of signature that takes the i-th
+// argument, which is the last one for all surplus arguments of a variadic
+// signature, or nil if there is none.
+func parameterAt(signature *parser.CallableNode, i int) *parser.ParamNode {
+	if i < len(signature.Parameters) {
+		return signature.Parameters[i]
+	}
+	if last := len(signature.Parameters) - 1; last >= 0 && signature.Parameters[last].Variadic {
+		return signature.Parameters[last]
+	}
+	return nil
+}
+
+// InferReturnType infers the template parameters of signature from the
+// arguments and returns its return type with the templates substituted.
+func InferReturnType(params []*parser.TemplateParam, signature *parser.CallableNode, arguments []parser.Node) (parser.Node, error) {
+	substitutions, err := Infer(params, signature, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return Substitute(signature.ReturnType, params, substitutions)
+}
+
+func (e *Env) newInference(params []*parser.TemplateParam) *inference {
+	inf := &inference{env: e, templates: make(map[string]*templateBounds, len(params))}
+	for _, param := range params {
+		inf.templates[param.Name] = &templateBounds{}
+	}
+	return inf
+}
+
+func (inf *inference) template(t Type) *templateBounds {
+	switch t := t.(type) {
+	case *TemplateType:
+		return inf.templates[t.Name]
+	case *ObjectType:
+		if len(t.TypeArguments) == 0 {
+			return inf.templates[t.Class]
+		}
+	}
+	return nil
+}
+
+// unify matches the parameter type against the argument type and records
+// the argument parts found at template positions. Contravariant positions,
+// like callable parameters, yield upper bounds instead of lower bounds. Each
+// element of a union argument contributes its own lower bounds.
+func (inf *inference) unify(parameter, argument Type, contravariant bool) {
+	if bounds := inf.template(parameter); bounds != nil {
+		if contravariant {
+			bounds.upper = append(bounds.upper, argument)
+		} else {
+			bounds.lower = append(bounds.lower, argument)
+		}
+		return
+	}
+	if a, ok := argument.(*UnionType); ok && !contravariant {
+		if _, ok := parameter.(*UnionType); !ok {
+			for _, element := range a.Types {
+				inf.unify(parameter, element, contravariant)
+			}
+			return
+		}
+	}
+	switch p := parameter.(type) {
+	case *UnionType:
+		inf.unifyUnion(p, argument, contravariant)
+	case *ArrayType:
+		switch a := argument.(type) {
+		case *ArrayType:
+			inf.unify(p.Key, a.Key, contravariant)
+			inf.unify(p.Value, a.Value, contravariant)
+		case *ShapeType:
+			array := inf.env.shapeToArray(a)
+			inf.unify(p.Key, array.Key, contravariant)
+			inf.unify(p.Value, array.Value, contravariant)
+		}
+	case *IterableType:
+		switch a := argument.(type) {
+		case *IterableType:
+			inf.unify(p.Key, a.Key, contravariant)
+			inf.unify(p.Value, a.Value, contravariant)
+		case *ArrayType:
+			inf.unify(p.Key, a.Key, contravariant)
+			inf.unify(p.Value, a.Value, contravariant)
+		case *ShapeType:
+			array := inf.env.shapeToArray(a)
+			inf.unify(p.Key, array.Key, contravariant)
+			inf.unify(p.Value, array.Value, contravariant)
+		}
+	case *ShapeType:
+		if a, ok := argument.(*ShapeType); ok {
+			inf.unifyMembers(p.Members, a.Members, contravariant)
+		}
+	case *ObjectShapeType:
+		if a, ok := argument.(*ObjectShapeType); ok {
+			inf.unifyMembers(p.Properties, a.Properties, contravariant)
+		}
+	case *ObjectType:
+		if a, ok := argument.(*ObjectType); ok && sameClass(p.Class, a.Class) && len(p.TypeArguments) == len(a.TypeArguments) {
+			for i, typeArgument := range p.TypeArguments {
+				inf.unify(typeArgument, a.TypeArguments[i], contravariant)
+			}
+		}
+	case *ClassStringType:
+		if a, ok := argument.(*ClassStringType); ok && p.Class != nil && a.Class != nil {
+			inf.unify(p.Class, a.Class, contravariant)
+		}
+	case *CallableType:
+		a, ok := argument.(*CallableType)
+		if !ok || p.ReturnType == nil || a.ReturnType == nil {
+			return
+		}
+		for i, parameter := range p.Parameters {
+			if actual := paramAt(a.Parameters, i); actual != nil {
+				inf.unify(parameter.Type, actual.Type, !contravariant)
+			}
+		}
+		inf.unify(p.ReturnType, a.ReturnType, contravariant)
+	}
+}
+
+func (inf *inference) unifyMembers(parameters, arguments []*ShapeMember, contravariant bool) {
+	for _, member := range parameters {
+		if argument := findMember(arguments, member.Key); argument != nil {
+			inf.unify(member.Value, argument.Value, contravariant)
+		}
+	}
+}
+
+// unifyUnion matches the argument elements that are not covered by the
+// concrete elements of the parameter union against its template elements.
+func (inf *inference) unifyUnion(parameter *UnionType, argument Type, contravariant bool) {
+	var concrete, templated []Type
+	for _, element := range parameter.Types {
+		if inf.mentionsTemplate(element) {
+			templated = append(templated, element)
+		} else {
+			concrete = append(concrete, element)
+		}
+	}
+	if len(templated) == 0 {
+		return
+	}
+	remaining := argument
+	for _, element := range concrete {
+		remaining = inf.env.Remove(remaining, element)
+	}
+	if _, never := remaining.(*NeverType); never {
+		return
+	}
+	for _, element := range templated {
+		inf.unify(element, remaining, contravariant)
+	}
+}
+
+func (inf *inference) mentionsTemplate(t Type) bool {
+	if inf.template(t) != nil {
+		return true
+	}
+	found := false
+	mapChildren(t, func(child Type) Type {
+		found = found || inf.mentionsTemplate(child)
+		return child
+	})
+	return found
+}
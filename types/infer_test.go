@@ -0,0 +1,188 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func simple(name string) parser.Node {
+	return parser.NewSimpleNode(name)
+}
+
+func generic(name string, args ...parser.Node) parser.Node {
+	return parser.NewGenericNode(name, args)
+}
+
+func callable(returnType parser.Node, params ...parser.Node) *parser.CallableNode {
+	parameters := make([]*parser.ParamNode, len(params))
+	for i, param := range params {
+		parameters[i] = parser.NewParam(param)
+	}
+	return parser.NewCallableNode(returnType, parameters).(*parser.CallableNode)
+}
+
+func TestInferReturnType(t *testing.T) {
+	templates := []*parser.TemplateParam{parser.NewTemplateParam("T", nil), parser.NewTemplateParam("U", nil)}
+	tests := []struct {
+		name      string
+		signature *parser.CallableNode
+		arguments []parser.Node
+		want      string
+	}{
+		{
+			"map",
+			callable(generic("list", simple("U")), generic("list", simple("T")), callable(simple("U"), simple("T"))),
+			[]parser.Node{generic("list", simple("int")), callable(simple("string"), simple("int"))},
+			"list<string>",
+		},
+		{
+			"identity",
+			callable(simple("T"), simple("T")),
+			[]parser.Node{parser.NewStringLiteralNode("foo")},
+			"\"foo\"",
+		},
+		{
+			"union of lower bounds",
+			callable(generic("list", simple("T")), simple("T"), simple("T")),
+			[]parser.Node{simple("int"), simple("string")},
+			"list<int | string>",
+		},
+		{
+			"nullable parameter",
+			callable(simple("T"), parser.NewUnionNode(simple("T"), simple("null"))),
+			[]parser.Node{parser.NewUnionNode(simple("int"), simple("null"))},
+			"int",
+		},
+		{
+			"array shape",
+			callable(simple("U"), parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+				parser.NewMember("key", simple("T")),
+				parser.NewMember("value", simple("U")),
+			})),
+			[]parser.Node{parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+				parser.NewMember("key", simple("string")),
+				parser.NewMember("value", simple("float")),
+			})},
+			"float",
+		},
+		{
+			"generic array from list shape",
+			callable(simple("T"), generic("array", simple("int"), simple("T"))),
+			[]parser.Node{parser.NewCurlyListNode("array", []parser.Node{simple("int"), simple("bool")})},
+			"int | bool",
+		},
+		{
+			"generic class",
+			callable(simple("T"), generic("Collection", simple("T"))),
+			[]parser.Node{generic("Collection", simple("Foo"))},
+			"Foo",
+		},
+		{
+			"only contravariant use",
+			callable(simple("T"), callable(simple("void"), simple("T"))),
+			[]parser.Node{callable(simple("void"), simple("int"))},
+			"int",
+		},
+		{
+			"intersection of upper bounds",
+			callable(simple("T"), callable(simple("void"), simple("T")), callable(simple("void"), simple("T"))),
+			[]parser.Node{callable(simple("void"), simple("int")), callable(simple("void"), simple("positive-int"))},
+			"positive-int",
+		},
+		{
+			"variadic parameter",
+			parser.NewCallableNode(generic("list", simple("T")), []*parser.ParamNode{parser.NewVariadicParam(simple("T"))}).(*parser.CallableNode),
+			[]parser.Node{simple("int"), simple("string"), simple("float")},
+			"list<int | string | float>",
+		},
+		{
+			"union argument",
+			callable(simple("T"), generic("list", simple("T")), callable(simple("bool"), simple("mixed"))),
+			[]parser.Node{
+				parser.NewUnionNode(generic("list", simple("int")), generic("list", simple("string"))),
+				callable(simple("bool"), simple("mixed")),
+			},
+			"int | string",
+		},
+		{
+			"iterable from shape",
+			callable(simple("T"), generic("iterable", simple("string"), simple("T"))),
+			[]parser.Node{parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{parser.NewMember("a", simple("int"))})},
+			"int",
+		},
+		{
+			"not inferable",
+			callable(simple("T")),
+			nil,
+			"mixed",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := types.InferReturnType(templates, test.signature, test.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != test.want {
+				t.Errorf("InferReturnType(%s) = %s, want %s", test.signature, got, test.want)
+			}
+		})
+	}
+}
+
+func TestInfer_Conflicts(t *testing.T) {
+	templates := []*parser.TemplateParam{parser.NewTemplateParam("T", simple("object"))}
+	tests := []struct {
+		name      string
+		signature *parser.CallableNode
+		arguments []parser.Node
+		want      string
+	}{
+		{
+			"lower bound exceeds upper bound",
+			callable(simple("void"), generic("list", simple("T")), callable(simple("void"), simple("T"))),
+			[]parser.Node{
+				generic("list", parser.NewUnionNode(simple("Foo"), simple("Bar"))),
+				callable(simple("void"), simple("Foo")),
+			},
+			"template T: inferred Foo | Bar is not a subtype of Foo",
+		},
+		{
+			"template bound",
+			callable(simple("T"), simple("T")),
+			[]parser.Node{simple("int")},
+			"int does not satisfy the bound object of template T",
+		},
+		{
+			"argument mismatch",
+			callable(simple("T"), simple("T"), simple("int")),
+			[]parser.Node{simple("Foo"), simple("string")},
+			"argument 2: string is not a subtype of int",
+		},
+		{
+			"surplus arguments",
+			callable(simple("T"), simple("T")),
+			[]parser.Node{simple("Foo"), simple("Bar")},
+			"expected at most 1 arguments, got 2",
+		},
+		{
+			"missing arguments",
+			callable(simple("T"), simple("T")),
+			nil,
+			"expected at least 1 arguments, got 0",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := types.Infer(templates, test.signature, test.arguments)
+			if _, ok := err.(*types.InferenceError); !ok {
+				t.Fatalf("expected an InferenceError, got %v", err)
+			}
+			if err.Error() != test.want {
+				t.Errorf("Infer(%s) error = %s, want %s", test.signature, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func (e *Env) keyOf(t Type) (Type, error) {
+	switch t := t.(type) {
+	case *ShapeType:
+		keys := make([]Type, len(t.Members))
+		for i, member := range t.Members {
+			keys[i] = shapeKeyType(member.Key)
+		}
+		if !t.Sealed {
+			key, _ := t.extra()
+			keys = append(keys, key)
+		}
+		return e.Simplify(union(keys)), nil
+	case *ArrayType:
+		return t.Key, nil
+	case *IterableType:
+		return t.Key, nil
+	case *UnionType:
+		return e.mapUnion(t, e.keyOf)
+	}
+	return nil, fmt.Errorf("cannot get the keys of %s", t)
+}
+
+func (e *Env) valueOf(t Type) (Type, error) {
+	switch t := t.(type) {
+	case *ShapeType:
+		values := make([]Type, len(t.Members))
+		for i, member := range t.Members {
+			values[i] = member.Value
+		}
+		if !t.Sealed {
+			_, value := t.extra()
+			values = append(values, value)
+		}
+		return e.Simplify(union(values)), nil
+	case *ArrayType:
+		return t.Value, nil
+	case *IterableType:
+		return t.Value, nil
+	case *UnionType:
+		return e.mapUnion(t, e.valueOf)
+	case *ObjectType:
+		if enum := e.enum(t.Class); enum != nil && len(t.TypeArguments) == 0 {
+			return e.enumValues(enum, enum.Cases)
+		}
+	case *EnumCaseType:
+		if enum := e.enum(t.Enum); enum != nil {
+			if c := enum.Case(t.Case); c != nil {
+				return e.enumValues(enum, []*EnumCase{c})
+			}
+		}
+	}
+	return nil, fmt.Errorf("cannot get the values of %s", t)
+}
+
+func (e *Env) enumValues(enum *Enum, cases []*EnumCase) (Type, error) {
+	if !enum.IsBacked() {
+		return nil, fmt.Errorf("cannot get the values of %s, it is not a backed enum", enum.Name)
+	}
+	values := make([]Type, len(cases))
+	for i, c := range cases {
+		value, err := e.Resolve(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return e.Simplify(union(values)), nil
+}
+
+func (e *Env) mapUnion(u *UnionType, f func(Type) (Type, error)) (Type, error) {
+	elements := make([]Type, len(u.Types))
+	for i, element := range u.Types {
+		mapped, err := f(element)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = mapped
+	}
+	return e.Simplify(union(elements)), nil
+}
+
+func (e *Env) classConstants(class string) map[string]parser.Node {
+	for name, constants := range e.Constants {
+		if sameClass(name, class) {
+			return constants
+		}
+	}
+	return nil
+}
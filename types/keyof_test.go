@@ -0,0 +1,68 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func TestEnv_Resolve_KeyOfValueOf(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"key-of<array{a: int, b?: string}>", "\"a\" | \"b\""},
+		{"value-of<array{a: int, b?: string}>", "int | string"},
+		{"key-of<array{int, string}>", "0 | 1"},
+		{"value-of<array{int, int}>", "int"},
+		{"key-of<array{}>", "never"},
+		{"key-of<list<string>>", "non-negative-int"},
+		{"value-of<list<string>>", "string"},
+		{"key-of<array<string, int>>", "string"},
+		{"value-of<non-empty-array<string, int>>", "int"},
+		{"key-of<iterable<Foo, Bar>>", "Foo"},
+		{"key-of<array{a: int} | array{b: int}>", "\"a\" | \"b\""},
+		{"key-of<Config::DEFAULTS>", "\"host\" | \"port\""},
+		{"value-of<Config::DEFAULTS>", "\"localhost\" | 80"},
+		{"value-of<Config::LEVELS>", "\"debug\" | \"info\""},
+		{"Config::TIMEOUT", "30"},
+		{"Config::*TIMEOUT", "30"},
+		{"value-of<Status>", "\"active\" | \"inactive\" | \"archived\""},
+		{"value-of<Status::Inactive>", "\"inactive\""},
+		{"list<key-of<array{a: int}>>", "list<\"a\">"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := envResolve(t, env, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_Resolve_KeyOfValueOfErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"key-of<int>", "cannot get the keys of int"},
+		{"value-of<Suit>", "cannot get the values of Suit, it is not a backed enum"},
+		{"key-of<Status>", "cannot get the keys of Status"},
+		{"value-of<Config::MISSING>", "undefined constant Config::MISSING"},
+		{"key-of<array{a: int}, int>", "key-of expects 1 type arguments, got 2 in key-of<array{a: int}, int>"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			node, err := parser.Parse(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = env.Resolve(node)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("Resolve(%s) error = %v, want %s", test.src, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package types
+
+// Join is (&Env{}).Join.
+func Join(a, b Type) Type {
+	return (&Env{}).Join(a, b)
+}
+
+// Join returns the least upper bound of a and b: the most specific type that
+// both a and b are subtypes of.
+func (e *Env) Join(a, b Type) Type {
+	if e.IsSubtype(a, b) {
+		return b
+	}
+	if e.IsSubtype(b, a) {
+		return a
+	}
+	joined := e.join(a, b)
+	if joined == nil || !e.IsSubtype(a, joined) || !e.IsSubtype(b, joined) {
+		return e.Simplify(&UnionType{Types: []Type{a, b}})
+	}
+	return joined
+}
+
+func (e *Env) join(a, b Type) Type {
+	switch a := a.(type) {
+	case *ShapeType:
+		switch b := b.(type) {
+		case *ShapeType:
+			return e.joinShapes(a, b)
+		case *ArrayType:
+			return e.joinArrays(e.shapeToArray(a), b)
+		}
+	case *ArrayType:
+		switch b := b.(type) {
+		case *ArrayType:
+			return e.joinArrays(a, b)
+		case *ShapeType:
+			return e.joinArrays(a, e.shapeToArray(b))
+		}
+	case *ObjectShapeType:
+		if b, ok := b.(*ObjectShapeType); ok {
+			return e.joinObjectShapes(a, b)
+		}
+	case *IterableType:
+		if b, ok := b.(*IterableType); ok {
+			return &IterableType{Key: e.Join(a.Key, b.Key), Value: e.Join(a.Value, b.Value)}
+		}
+	}
+	return nil
+}
+
+func (e *Env) joinShapes(a, b *ShapeType) Type {
+	members := make([]*ShapeMember, 0, len(a.Members)+len(b.Members))
+	for _, member := range a.Members {
+		members = append(members, e.joinMember(member, findMember(b.Members, member.Key), b))
+	}
+	for _, member := range b.Members {
+		if findMember(a.Members, member.Key) == nil {
+			members = append(members, e.joinMember(member, nil, a))
+		}
+	}
+	joined := &ShapeType{Members: members, Sealed: a.Sealed && b.Sealed, List: a.List && b.List}
+	switch {
+	case joined.Sealed:
+	case a.Sealed:
+		joined.ExtraKey, joined.ExtraValue = b.ExtraKey, b.ExtraValue
+	case b.Sealed:
+		joined.ExtraKey, joined.ExtraValue = a.ExtraKey, a.ExtraValue
+	default:
+		aKey, aValue := a.extra()
+		bKey, bValue := b.extra()
+		joined.ExtraKey, joined.ExtraValue = extras(e.Join(aKey, bKey), e.Join(aValue, bValue))
+	}
+	return joined
+}
+
+// joinMember joins a member with its counterpart in the other shape. A
+// member missing there becomes optional and, if the other shape is unsealed,
+// may also hold its extra value type.
+func (e *Env) joinMember(member, other *ShapeMember, otherShape *ShapeType) *ShapeMember {
+	if other != nil {
+		return &ShapeMember{
+			Key:      member.Key,
+			Value:    e.Join(member.Value, other.Value),
+			Optional: member.Optional || other.Optional,
+			Node:     member.Node,
+		}
+	}
+	value := member.Value
+	if key, extra := otherShape.extra(); !otherShape.Sealed && e.IsSubtype(shapeKeyType(member.Key), key) {
+		value = e.Join(value, extra)
+	}
+	return &ShapeMember{Key: member.Key, Value: value, Optional: true, Node: member.Node}
+}
+
+func (e *Env) joinObjectShapes(a, b *ObjectShapeType) Type {
+	var properties []*ShapeMember
+	for _, property := range a.Properties {
+		other := findMember(b.Properties, property.Key)
+		if other == nil {
+			continue
+		}
+		properties = append(properties, &ShapeMember{
+			Key:      property.Key,
+			Value:    e.Join(property.Value, other.Value),
+			Optional: property.Optional || other.Optional,
+			Node:     property.Node,
+		})
+	}
+	return &ObjectShapeType{Properties: properties}
+}
+
+func (e *Env) joinArrays(a, b *ArrayType) Type {
+	return &ArrayType{
+		Key:      e.Join(a.Key, b.Key),
+		Value:    e.Join(a.Value, b.Value),
+		List:     a.List && b.List,
+		NonEmpty: a.NonEmpty && b.NonEmpty,
+	}
+}
+
+// shapeToArray generalizes an array shape to the generic array type with
+// the same keys and values.
+func (e *Env) shapeToArray(shape *ShapeType) *ArrayType {
+	keys := make([]Type, len(shape.Members))
+	values := make([]Type, len(shape.Members))
+	for i, member := range shape.Members {
+		keys[i] = shapeKeyType(member.Key)
+		values[i] = member.Value
+	}
+	if !shape.Sealed {
+		key, value := shape.extra()
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	list := isListShape(shape)
+	key := e.Simplify(&UnionType{Types: keys})
+	if list {
+		key = NewIntRangeType(intPtr(0), nil)
+	}
+	return &ArrayType{
+		Key:      key,
+		Value:    e.Simplify(&UnionType{Types: values}),
+		List:     list,
+		NonEmpty: hasRequiredMember(shape.Members),
+	}
+}
+
+// Meet is (&Env{}).Meet.
+func Meet(a, b Type) Type {
+	return (&Env{}).Meet(a, b)
+}
+
+// Meet returns the greatest lower bound of a and b: the most general type
+// that is a subtype of both a and b.
+func (e *Env) Meet(a, b Type) Type {
+	if e.IsSubtype(a, b) {
+		return a
+	}
+	if e.IsSubtype(b, a) {
+		return b
+	}
+	met := e.meet(a, b)
+	if !e.IsSubtype(met, a) || !e.IsSubtype(met, b) {
+		return &IntersectionType{Types: []Type{a, b}}
+	}
+	return met
+}
+
+func (e *Env) meet(a, b Type) Type {
+	if u, ok := a.(*UnionType); ok {
+		return e.meetUnion(u, b)
+	}
+	if u, ok := b.(*UnionType); ok {
+		return e.meetUnion(u, a)
+	}
+	switch a := a.(type) {
+	case *ShapeType:
+		switch b := b.(type) {
+		case *ShapeType:
+			return e.meetShapes(a, b)
+		case *ArrayType:
+			return e.meetShapeWithArray(a, b)
+		}
+	case *ArrayType:
+		switch b := b.(type) {
+		case *ArrayType:
+			return e.meetArrays(a, b)
+		case *ShapeType:
+			return e.meetShapeWithArray(b, a)
+		}
+	case *IterableType:
+		switch b := b.(type) {
+		case *IterableType:
+			return &IterableType{Key: e.Meet(a.Key, b.Key), Value: e.Meet(a.Value, b.Value)}
+		case *ArrayType:
+			return e.meetArrays(&ArrayType{Key: a.Key, Value: a.Value}, b)
+		}
+	}
+	return e.Simplify(&IntersectionType{Types: []Type{a, b}})
+}
+
+func (e *Env) meetUnion(u *UnionType, t Type) Type {
+	elements := make([]Type, len(u.Types))
+	for i, element := range u.Types {
+		elements[i] = e.Meet(element, t)
+	}
+	return e.Simplify(&UnionType{Types: elements})
+}
+
+// meetShapes meets the members of two shapes. A member missing from an
+// unsealed shape is met with its extra value type.
+func (e *Env) meetShapes(a, b *ShapeType) Type {
+	var members []*ShapeMember
+	add := func(member *ShapeMember, other *ShapeMember, otherShape *ShapeType) bool {
+		value, optional := member.Value, member.Optional
+		switch {
+		case other != nil:
+			value = e.Meet(member.Value, other.Value)
+			optional = member.Optional && other.Optional
+		case otherShape.Sealed:
+			value = NewNeverType()
+		default:
+			key, extra := otherShape.extra()
+			value = e.Meet(member.Value, extra)
+			if !e.IsSubtype(shapeKeyType(member.Key), key) {
+				value = NewNeverType()
+			}
+		}
+		if _, never := value.(*NeverType); never {
+			return optional
+		}
+		members = append(members, &ShapeMember{Key: member.Key, Value: value, Optional: optional, Node: member.Node})
+		return true
+	}
+	for _, member := range a.Members {
+		if !add(member, findMember(b.Members, member.Key), b) {
+			return NewNeverType()
+		}
+	}
+	for _, member := range b.Members {
+		if findMember(a.Members, member.Key) == nil && !add(member, nil, a) {
+			return NewNeverType()
+		}
+	}
+	shape := &ShapeType{Members: members, Sealed: a.Sealed || b.Sealed, List: a.List || b.List}
+	if !shape.Sealed {
+		aKey, aValue := a.extra()
+		bKey, bValue := b.extra()
+		shape.ExtraKey, shape.ExtraValue = extras(e.Meet(aKey, bKey), e.Meet(aValue, bValue))
+	}
+	return shape
+}
+
+// extras returns the extra key and value types of an unsealed shape, leaving
+// out the ones that do not restrict anything.
+func extras(key, value Type) (Type, Type) {
+	if IsSubtype(NewArrayKeyType(), key) {
+		key = nil
+	}
+	if _, ok := value.(*MixedType); ok {
+		value = nil
+	}
+	return key, value
+}
+
+func (e *Env) meetShapeWithArray(shape *ShapeType, array *ArrayType) Type {
+	if array.List && !isListShape(shape) {
+		return NewNeverType()
+	}
+	var members []*ShapeMember
+	for _, member := range shape.Members {
+		value := e.Meet(member.Value, array.Value)
+		_, never := value.(*NeverType)
+		if never || !e.IsSubtype(shapeKeyType(member.Key), array.Key) {
+			if !member.Optional {
+				return NewNeverType()
+			}
+			continue
+		}
+		members = append(members, &ShapeMember{Key: member.Key, Value: value, Optional: member.Optional, Node: member.Node})
+	}
+	met := &ShapeType{Members: members, Sealed: shape.Sealed, List: shape.List || array.List}
+	if !shape.Sealed {
+		key, value := shape.extra()
+		met.ExtraKey, met.ExtraValue = extras(e.Meet(key, array.Key), e.Meet(value, array.Value))
+	}
+	return met
+}
+
+func (e *Env) meetArrays(a, b *ArrayType) Type {
+	return &ArrayType{
+		Key:      e.Meet(a.Key, b.Key),
+		Value:    e.Meet(a.Value, b.Value),
+		List:     a.List || b.List,
+		NonEmpty: a.NonEmpty || b.NonEmpty,
+	}
+}
@@ -0,0 +1,186 @@
+package types_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		a    types.Type
+		b    types.Type
+		want string
+	}{
+		{types.NewIntType(), types.NewIntRangeType(intPtr(1), nil), "int"},
+		{types.NewStringLiteralType("a"), types.NewStringLiteralType("b"), "\"a\" | \"b\""},
+		{types.NewStringLiteralType("a"), types.NewStringType(), "string"},
+		{types.NewIntRangeType(intPtr(0), intPtr(5)), types.NewIntRangeType(intPtr(6), intPtr(9)), "int<0, 9>"},
+		{
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("a", types.NewIntType())}),
+			types.NewShapeType([]*types.ShapeMember{
+				types.NewShapeMember("a", types.NewStringType()),
+				types.NewShapeMember("b", types.NewIntType()),
+			}),
+			"array{a: int | string, b?: int}",
+		},
+		{
+			types.NewShapeType([]*types.ShapeMember{
+				types.NewShapeMember("0", types.NewIntType()),
+				types.NewShapeMember("1", types.NewStringType()),
+			}),
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("0", types.NewIntType())}),
+			"array{0: int, 1?: string}",
+		},
+		{
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("a", types.NewIntType())}),
+			types.NewArrayType(types.NewStringType(), types.NewStringType()),
+			"array<string, int | string>",
+		},
+		{
+			types.NewListType(types.NewIntType()),
+			types.NewNonEmptyListType(types.NewStringType()),
+			"list<int | string>",
+		},
+		{
+			types.NewObjectShapeType([]*types.ShapeMember{
+				types.NewShapeMember("a", types.NewIntType()),
+				types.NewShapeMember("b", types.NewIntType()),
+			}),
+			types.NewObjectShapeType([]*types.ShapeMember{types.NewShapeMember("a", types.NewStringType())}),
+			"object{a: int | string}",
+		},
+		{
+			types.NewObjectType("Box", types.NewIntType()),
+			types.NewObjectType("Box", types.NewStringType()),
+			"Box<int> | Box<string>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.a.String()+" v "+test.b.String(), func(t *testing.T) {
+			if got := types.Join(test.a, test.b); got.String() != test.want {
+				t.Errorf("Join(%s, %s) = %s, want %s", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMeet(t *testing.T) {
+	tests := []struct {
+		a    types.Type
+		b    types.Type
+		want string
+	}{
+		{types.NewIntType(), types.NewIntRangeType(intPtr(1), nil), "positive-int"},
+		{types.NewStringLiteralType("a"), types.NewStringLiteralType("b"), "never"},
+		{types.NewStringLiteralType("a"), types.NewStringType(), "\"a\""},
+		{
+			types.NewUnionType(types.NewIntType(), types.NewStringType(), types.NewNullType()),
+			types.NewUnionType(types.NewStringType(), types.NewNullType()),
+			"string | null",
+		},
+		{
+			types.NewShapeType([]*types.ShapeMember{
+				types.NewShapeMember("a", types.NewIntType()),
+				types.NewOptionalShapeMember("b", types.NewStringType()),
+			}),
+			types.NewShapeType([]*types.ShapeMember{
+				types.NewShapeMember("a", types.NewIntRangeType(intPtr(1), nil)),
+				types.NewOptionalShapeMember("c", types.NewIntType()),
+			}),
+			"array{a: positive-int}",
+		},
+		{
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("a", types.NewIntType())}),
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("b", types.NewIntType())}),
+			"never",
+		},
+		{
+			types.NewShapeType([]*types.ShapeMember{
+				types.NewShapeMember("a", types.NewIntType()),
+				types.NewOptionalShapeMember("b", types.NewStringType()),
+			}),
+			types.NewArrayType(types.NewStringType(), types.NewIntType()),
+			"array{a: int}",
+		},
+		{
+			types.NewArrayType(types.NewIntType(), types.NewUnionType(types.NewIntType(), types.NewStringType())),
+			types.NewListType(types.NewStringType()),
+			"list<string>",
+		},
+		{types.NewObjectType("Foo"), types.NewObjectType("Bar"), "Foo & Bar"},
+	}
+	for _, test := range tests {
+		t.Run(test.a.String()+" ^ "+test.b.String(), func(t *testing.T) {
+			if got := types.Meet(test.a, test.b); got.String() != test.want {
+				t.Errorf("Meet(%s, %s) = %s, want %s", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
+
+func TestJoinMeet_Bounds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		a, b := randomType(r, 2), randomType(r, 2)
+		if join := types.Join(a, b); !types.IsSubtype(a, join) || !types.IsSubtype(b, join) {
+			t.Errorf("Join(%s, %s) = %s is not an upper bound", a, b, join)
+		}
+		if meet := types.Meet(a, b); !types.IsSubtype(meet, a) || !types.IsSubtype(meet, b) {
+			t.Errorf("Meet(%s, %s) = %s is not a lower bound", a, b, meet)
+		}
+		if join, meet := types.Join(a, a), types.Meet(a, a); join.String() != a.String() || meet.String() != a.String() {
+			t.Errorf("Join and Meet of %s with itself are %s and %s", a, join, meet)
+		}
+	}
+}
+
+func randomType(r *rand.Rand, depth int) types.Type {
+	atoms := []func() types.Type{
+		types.NewIntType,
+		types.NewStringType,
+		types.NewNonEmptyStringType,
+		types.NewNullType,
+		types.NewBoolType,
+		types.NewFloatType,
+		func() types.Type { return types.NewIntRangeType(intPtr(1), nil) },
+		func() types.Type { return types.NewIntRangeType(intPtr(0), intPtr(10)) },
+		func() types.Type { return types.NewIntLiteralType(r.Intn(3)) },
+		func() types.Type { return types.NewStringLiteralType([]string{"", "a", "b"}[r.Intn(3)]) },
+		func() types.Type { return types.NewBoolLiteralType(r.Intn(2) == 0) },
+		func() types.Type { return types.NewObjectType([]string{"Foo", "Bar"}[r.Intn(2)]) },
+	}
+	if depth == 0 || r.Intn(3) == 0 {
+		return atoms[r.Intn(len(atoms))]()
+	}
+	switch r.Intn(5) {
+	case 0:
+		return types.NewListType(randomType(r, depth-1))
+	case 1:
+		return types.NewArrayType(types.NewUnionType(types.NewIntType(), types.NewStringType()), randomType(r, depth-1))
+	case 2:
+		var members []*types.ShapeMember
+		for _, key := range []string{"a", "b", "c"} {
+			switch r.Intn(3) {
+			case 0:
+				members = append(members, types.NewShapeMember(key, randomType(r, depth-1)))
+			case 1:
+				members = append(members, types.NewOptionalShapeMember(key, randomType(r, depth-1)))
+			}
+		}
+		switch r.Intn(4) {
+		case 0:
+			return types.NewUnsealedShapeType(members, nil, nil)
+		case 1:
+			return types.NewUnsealedShapeType(members, types.NewStringType(), randomType(r, depth-1))
+		}
+		return types.NewShapeType(members)
+	case 3:
+		return types.NewShapeType([]*types.ShapeMember{
+			types.NewShapeMember("0", randomType(r, depth-1)),
+			types.NewShapeMember("1", randomType(r, depth-1)),
+		})
+	}
+	return types.NewUnionType(randomType(r, depth-1), randomType(r, depth-1))
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+// NameResolver turns class names as written in a file into fully qualified
+// names, using the namespace and the use imports of the file. Resolved names
+// have no leading backslash, like the names in a ClassRegistry.
+type NameResolver struct {
+	Namespace string
+	uses      map[string]string
+	templates map[string]bool
+}
+
+// NewNameResolver creates a resolver for namespace with uses mapping aliases
+// to the names they import.
+func NewNameResolver(namespace string, uses map[string]string) *NameResolver {
+	r := &NameResolver{Namespace: strings.Trim(namespace, "\\"), uses: map[string]string{}}
+	for alias, name := range uses {
+		r.Use(name, alias)
+	}
+	return r
+}
+
+// Use imports name under alias, or under its last segment if alias is empty,
+// like use App\User as Alias.
+func (r *NameResolver) Use(name, alias string) {
+	name = strings.TrimPrefix(name, "\\")
+	if alias == "" {
+		alias = name[strings.LastIndex(name, "\\")+1:]
+	}
+	r.uses[strings.ToLower(alias)] = name
+}
+
+// UseGroup imports the clauses of a group use like
+// use App\{User, Entity\Admin as Root}, where each clause is a name relative
+// to prefix, optionally followed by an alias.
+func (r *NameResolver) UseGroup(prefix string, clauses ...string) {
+	prefix = strings.TrimSuffix(prefix, "\\") + "\\"
+	for _, clause := range clauses {
+		name, alias := clause, ""
+		if fields := strings.Fields(clause); len(fields) == 3 && strings.EqualFold(fields[1], "as") {
+			name, alias = fields[0], fields[2]
+		}
+		r.Use(prefix+name, alias)
+	}
+}
+
+// WithTemplates returns a resolver that leaves the given template names
+// untouched, as they refer to templates instead of classes.
+func (r *NameResolver) WithTemplates(templates ...*parser.TemplateParam) *NameResolver {
+	if len(templates) == 0 {
+		return r
+	}
+	scoped := *r
+	scoped.templates = make(map[string]bool, len(r.templates)+len(templates))
+	for name := range r.templates {
+		scoped.templates[name] = true
+	}
+	for _, template := range templates {
+		scoped.templates[template.Name] = true
+	}
+	return &scoped
+}
+
+// ResolveClass returns the fully qualified form of a class name following
+// the PHP rules: fully qualified names are kept, names starting with an
+// import are expanded and all other names are relative to the namespace.
+func (r *NameResolver) ResolveClass(name string) string {
+	if strings.HasPrefix(name, "\\") {
+		return name[1:]
+	}
+	if len(name) > len("namespace\\") && strings.EqualFold(name[:len("namespace\\")], "namespace\\") {
+		return r.qualify(name[len("namespace\\"):])
+	}
+	first, rest, qualified := strings.Cut(name, "\\")
+	if use, ok := r.uses[strings.ToLower(first)]; ok {
+		if qualified {
+			return use + "\\" + rest
+		}
+		return use
+	}
+	return r.qualify(name)
+}
+
+func (r *NameResolver) qualify(name string) string {
+	if r.Namespace == "" {
+		return name
+	}
+	return r.Namespace + "\\" + name
+}
+
+func (r *NameResolver) resolveName(name string) string {
+	if isKeyword(name) || r.templates[name] {
+		return name
+	}
+	return r.ResolveClass(name)
+}
+
+// isKeyword reports whether name is a built-in type or a name like self that
+// is not resolved against the namespace.
+func isKeyword(name string) bool {
+	lower := strings.ToLower(name)
+	if _, ok := simpleTypes[lower]; ok {
+		return true
+	}
+	switch lower {
+	case "int", "self", "static", "parent", "closure", "pure-closure":
+		return true
+	}
+	return isBuiltinGeneric(lower) || parser.IsCallableName(name)
+}
+
+// Resolve returns node with all class names replaced by their fully
+// qualified names. Built-in types, self, static, parent and templates are
+// left untouched.
+func (r *NameResolver) Resolve(node parser.Node) parser.Node {
+	switch n := node.(type) {
+	case *parser.IdentifierNode:
+		return &parser.IdentifierNode{Name: r.resolveName(n.Name), TypeArguments: r.resolveList(n.TypeArguments)}
+	case *parser.CurlyListNode:
+		return &parser.CurlyListNode{Name: n.Name, Elements: r.resolveList(n.Elements)}
+	case *parser.CurlyKeyValueNode:
+		members := make([]*parser.MemberNode, len(n.Members))
+		for i, member := range n.Members {
+			resolved := *member
+			resolved.Value = r.Resolve(member.Value)
+			members[i] = &resolved
+		}
+		resolved := &parser.CurlyKeyValueNode{Name: n.Name, Members: members, Sealed: n.Sealed}
+		if n.ExtraKey != nil {
+			resolved.ExtraKey = r.Resolve(n.ExtraKey)
+		}
+		if n.ExtraValue != nil {
+			resolved.ExtraValue = r.Resolve(n.ExtraValue)
+		}
+		return resolved
+	case *parser.CallableNode:
+		scoped := r.WithTemplates(n.Templates...)
+		templates := make([]*parser.TemplateParam, len(n.Templates))
+		for i, template := range n.Templates {
+			resolved := *template
+			if template.Bound != nil {
+				resolved.Bound = scoped.Resolve(template.Bound)
+			}
+			if template.Default != nil {
+				resolved.Default = scoped.Resolve(template.Default)
+			}
+			templates[i] = &resolved
+		}
+		parameters := make([]*parser.ParamNode, len(n.Parameters))
+		for i, parameter := range n.Parameters {
+			resolved := *parameter
+			resolved.Type = scoped.Resolve(parameter.Type)
+			parameters[i] = &resolved
+		}
+		resolved := &parser.CallableNode{Name: n.Name, Templates: templates, Parameters: parameters}
+		if n.ReturnType != nil {
+			resolved.ReturnType = scoped.Resolve(n.ReturnType)
+		}
+		return resolved
+	case *parser.UnionNode:
+		return &parser.UnionNode{Elements: r.resolveList(n.Elements)}
+	case *parser.IntersectionNode:
+		return &parser.IntersectionNode{Elements: r.resolveList(n.Elements)}
+	case *parser.VarianceNode:
+		return &parser.VarianceNode{Variance: n.Variance, Type: r.Resolve(n.Type)}
+	case *parser.ConstFetchNode:
+		return &parser.ConstFetchNode{Class: r.resolveName(n.Class), Name: n.Name}
+	case *parser.ConditionalNode:
+		return &parser.ConditionalNode{
+			Subject: r.Resolve(n.Subject),
+			Target:  r.Resolve(n.Target),
+			Negated: n.Negated,
+			If:      r.Resolve(n.If),
+			Else:    r.Resolve(n.Else),
+		}
+	case *parser.OffsetAccessNode:
+		return &parser.OffsetAccessNode{Type: r.Resolve(n.Type), Offset: r.Resolve(n.Offset)}
+	}
+	return node
+}
+
+func (r *NameResolver) resolveList(nodes []parser.Node) []parser.Node {
+	if nodes == nil {
+		return nil
+	}
+	resolved := make([]parser.Node, len(nodes))
+	for i, node := range nodes {
+		resolved[i] = r.Resolve(node)
+	}
+	return resolved
+}
@@ -0,0 +1,69 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestNameResolver_Resolve(t *testing.T) {
+	resolver := types.NewNameResolver("App\\Entity", map[string]string{
+		"Collection": "Doctrine\\Common\\Collections\\Collection",
+		"Carbon":     "\\Carbon\\CarbonImmutable",
+	})
+	resolver.Use("App\\Contract\\Identifiable", "")
+	resolver.UseGroup("App\\Enum", "Status", "Role as UserRole")
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"User", "App\\Entity\\User"},
+		{"\\User", "User"},
+		{"Sub\\User", "App\\Entity\\Sub\\User"},
+		{"namespace\\Sub\\User", "App\\Entity\\Sub\\User"},
+		{"collection<int, User>", "Doctrine\\Common\\Collections\\Collection<int, App\\Entity\\User>"},
+		{"Carbon", "Carbon\\CarbonImmutable"},
+		{"Identifiable&Status", "App\\Contract\\Identifiable & App\\Enum\\Status"},
+		{"UserRole::ADMIN | Status::*", "App\\Enum\\Role::ADMIN | App\\Enum\\Status::*"},
+		{"int|list<string>|array-key|non-empty-array<int, bool>|null", "int | list<string> | array-key | non-empty-array<int, bool> | null"},
+		{"class-string<User>", "class-string<App\\Entity\\User>"},
+		{"self|static|parent|self::FOO", "self | static | parent | self::FOO"},
+		{"Closure(User): void", "Closure(App\\Entity\\User): void"},
+		{"callable<T of User>(T): Role", "callable<T of App\\Entity\\User>(T): App\\Entity\\Role"},
+		{"array{user: User, roles: list<Role>, ...<string, User>}", "array{user: App\\Entity\\User, roles: list<App\\Entity\\Role>, ...<string, App\\Entity\\User>}"},
+		{"object{user: User}", "object{user: App\\Entity\\User}"},
+		{"($value is User ? Role : null)", "($value is App\\Entity\\User ? App\\Entity\\Role : null)"},
+		{"User['key']", "App\\Entity\\User[\"key\"]"},
+		{"Collection<covariant User, *>", "Doctrine\\Common\\Collections\\Collection<covariant App\\Entity\\User, *>"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			node, err := parser.Parse(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resolver.Resolve(node).String(); got != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNameResolver_WithTemplates(t *testing.T) {
+	resolver := types.NewNameResolver("App", nil).WithTemplates(parser.NewTemplateParam("T", nil))
+	node, err := parser.Parse("Collection<T, Item>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resolver.Resolve(node).String(), "App\\Collection<T, App\\Item>"; got != want {
+		t.Errorf("Resolve() = %s, want %s", got, want)
+	}
+}
+
+func TestNameResolver_GlobalNamespace(t *testing.T) {
+	resolver := types.NewNameResolver("", nil)
+	if got := resolver.ResolveClass("Foo\\Bar"); got != "Foo\\Bar" {
+		t.Errorf("ResolveClass(Foo\\Bar) = %s, want Foo\\Bar", got)
+	}
+}
@@ -0,0 +1,95 @@
+package types
+
+import "github.com/MidnightDesign/php-types-go/parser"
+
+// Remove is (&Env{}).Remove.
+func Remove(from, removed Type) Type {
+	return (&Env{}).Remove(from, removed)
+}
+
+// Remove returns the part of from that is not of type removed, e.g. the type
+// of a variable after a failed identity check against removed.
+func (e *Env) Remove(from, removed Type) Type {
+	if e.IsSubtype(from, removed) {
+		return NewNeverType()
+	}
+	if u, ok := removed.(*UnionType); ok {
+		for _, element := range u.Types {
+			from = e.Remove(from, element)
+		}
+		return from
+	}
+	switch f := from.(type) {
+	case *UnionType:
+		elements := make([]Type, len(f.Types))
+		for i, element := range f.Types {
+			elements[i] = e.Remove(element, removed)
+		}
+		return e.Simplify(&UnionType{Types: elements})
+	case *BoolType:
+		if literal, ok := removed.(*BoolLiteralType); ok {
+			return NewBoolLiteralType(!literal.Value)
+		}
+	case *IntType:
+		return removeInts(f, removed)
+	case *StringType:
+		if literal, ok := removed.(*StringLiteralType); ok && literal.Value == "" && !f.NonEmpty {
+			return &StringType{NonEmpty: true}
+		}
+	case *ArrayType:
+		if shape, ok := removed.(*ShapeType); ok && shape.Sealed && len(shape.Members) == 0 && !f.NonEmpty {
+			return &ArrayType{Key: f.Key, Value: f.Value, List: f.List, NonEmpty: true}
+		}
+	}
+	return from
+}
+
+func RemoveNode(from, removed parser.Node) (parser.Node, error) {
+	f, err := Resolve(from)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Resolve(removed)
+	if err != nil {
+		return nil, err
+	}
+	return ToNode(Remove(f, r)), nil
+}
+
+func removeInts(from *IntType, removed Type) Type {
+	switch removed.(type) {
+	case *IntType, *IntLiteralType:
+	default:
+		return from
+	}
+	if _, overlap := meetInts(from, removed); !overlap {
+		return from
+	}
+	min, max := intBounds(removed)
+	var parts []Type
+	if min != nil && (from.Min == nil || *from.Min < *min) {
+		parts = append(parts, intRange(from.Min, intPtr(*min-1)))
+	}
+	if max != nil && (from.Max == nil || *from.Max > *max) {
+		parts = append(parts, intRange(intPtr(*max+1), from.Max))
+	}
+	return union(parts)
+}
+
+func intRange(min, max *int) Type {
+	if min != nil && max != nil && *min == *max {
+		return NewIntLiteralType(*min)
+	}
+	return &IntType{Min: min, Max: max}
+}
+
+// Narrow is (&Env{}).Narrow.
+func Narrow(t, guard Type) (Type, Type) {
+	return (&Env{}).Narrow(t, guard)
+}
+
+// Narrow returns the type of a value of type t in both branches of a type
+// check against guard, like instanceof or an identity comparison.
+func (e *Env) Narrow(t, guard Type) (Type, Type) {
+	return e.Meet(t, guard), e.Remove(t, guard)
+}
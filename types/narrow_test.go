@@ -0,0 +1,104 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		from    types.Type
+		removed types.Type
+		want    string
+	}{
+		{
+			types.NewUnionType(types.NewIntType(), types.NewStringType(), types.NewNullType()),
+			types.NewNullType(),
+			"int | string",
+		},
+		{
+			types.NewUnionType(types.NewStringLiteralType("a"), types.NewStringLiteralType("b"), types.NewStringLiteralType("c")),
+			types.NewStringLiteralType("a"),
+			"\"b\" | \"c\"",
+		},
+		{
+			types.NewUnionType(types.NewIntLiteralType(1), types.NewIntLiteralType(2), types.NewNullType()),
+			types.NewUnionType(types.NewIntLiteralType(2), types.NewNullType()),
+			"1",
+		},
+		{types.NewIntType(), types.NewIntType(), "never"},
+		{types.NewIntType(), types.NewStringType(), "int"},
+		{types.NewBoolType(), types.NewBoolLiteralType(false), "true"},
+		{types.NewIntRangeType(intPtr(0), intPtr(10)), types.NewIntLiteralType(5), "int<0, 4> | int<6, 10>"},
+		{types.NewIntRangeType(intPtr(0), intPtr(10)), types.NewIntLiteralType(0), "int<1, 10>"},
+		{types.NewIntRangeType(intPtr(0), intPtr(10)), types.NewIntLiteralType(20), "int<0, 10>"},
+		{types.NewIntRangeType(intPtr(0), intPtr(2)), types.NewIntLiteralType(1), "0 | 2"},
+		{types.NewIntRangeType(intPtr(0), nil), types.NewIntLiteralType(0), "positive-int"},
+		{types.NewIntType(), types.NewIntRangeType(nil, intPtr(0)), "positive-int"},
+		{types.NewIntType(), types.NewIntRangeType(intPtr(1), intPtr(5)), "non-positive-int | int<6, max>"},
+		{types.NewStringType(), types.NewStringLiteralType(""), "non-empty-string"},
+		{types.NewListType(types.NewIntType()), types.NewShapeType(nil), "non-empty-list<int>"},
+	}
+	for _, test := range tests {
+		t.Run(test.from.String()+" - "+test.removed.String(), func(t *testing.T) {
+			if got := types.Remove(test.from, test.removed); got.String() != test.want {
+				t.Errorf("Remove(%s, %s) = %s, want %s", test.from, test.removed, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	from := parser.NewUnionNode(parser.NewSimpleNode("int"), parser.NewSimpleNode("string"), parser.NewSimpleNode("null"))
+	got, err := types.RemoveNode(from, parser.NewSimpleNode("null"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "int | string" {
+		t.Errorf("RemoveNode(%s, null) = %s, want int | string", from, got)
+	}
+}
+
+func TestNarrow(t *testing.T) {
+	tests := []struct {
+		t         types.Type
+		guard     types.Type
+		wantTrue  string
+		wantFalse string
+	}{
+		{
+			types.NewUnionType(types.NewIntType(), types.NewStringType(), types.NewNullType()),
+			types.NewNullType(),
+			"null",
+			"int | string",
+		},
+		{
+			types.NewUnionType(types.NewObjectType("Foo"), types.NewNullType()),
+			types.NewObjectType("Foo"),
+			"Foo",
+			"null",
+		},
+		{
+			types.NewIntRangeType(intPtr(0), nil),
+			types.NewIntLiteralType(0),
+			"0",
+			"positive-int",
+		},
+		{
+			types.NewStringType(),
+			types.NewStringLiteralType("a"),
+			"\"a\"",
+			"string",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.t.String()+" is "+test.guard.String(), func(t *testing.T) {
+			gotTrue, gotFalse := types.Narrow(test.t, test.guard)
+			if gotTrue.String() != test.wantTrue || gotFalse.String() != test.wantFalse {
+				t.Errorf("Narrow(%s, %s) = (%s, %s), want (%s, %s)", test.t, test.guard, gotTrue, gotFalse, test.wantTrue, test.wantFalse)
+			}
+		})
+	}
+}
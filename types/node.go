@@ -0,0 +1,153 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+// ToNode converts a type back into a syntax tree. It is the inverse of
+// Resolve, except that aliases like "boolean" or "array-key" are expanded.
+func ToNode(t Type) parser.Node {
+	switch t := t.(type) {
+	case *IntType:
+		if name := t.String(); !strings.HasPrefix(name, "int<") {
+			return parser.NewSimpleNode(name)
+		}
+		return parser.NewGenericNode("int", []parser.Node{intBoundNode(t.Min, "min"), intBoundNode(t.Max, "max")})
+	case *IntLiteralType:
+		return parser.NewIntLiteralNode(t.Value)
+	case *StringLiteralType:
+		return parser.NewStringLiteralNode(t.Value)
+	case *ClassStringType:
+		if t.Class == nil {
+			return parser.NewSimpleNode("class-string")
+		}
+		return parser.NewGenericNode("class-string", []parser.Node{ToNode(t.Class)})
+	case *ArrayType:
+		name := "array"
+		if t.List {
+			name = "list"
+		}
+		if t.NonEmpty {
+			name = "non-empty-" + name
+		}
+		if t.List {
+			return parser.NewGenericNode(name, []parser.Node{ToNode(t.Value)})
+		}
+		return parser.NewGenericNode(name, []parser.Node{ToNode(t.Key), ToNode(t.Value)})
+	case *IterableType:
+		return parser.NewGenericNode("iterable", []parser.Node{ToNode(t.Key), ToNode(t.Value)})
+	case *ShapeType:
+		name := "array"
+		if t.List {
+			name = "list"
+		}
+		if !t.Sealed {
+			node := &parser.CurlyKeyValueNode{Name: name, Members: memberNodes(t.Members)}
+			if t.ExtraKey != nil {
+				node.ExtraKey = ToNode(t.ExtraKey)
+			}
+			if t.ExtraValue != nil {
+				node.ExtraValue = ToNode(t.ExtraValue)
+			}
+			return node
+		}
+		if isListShape(t) && !hasOptionalMember(t.Members) {
+			elements := make([]parser.Node, len(t.Members))
+			for i, member := range t.Members {
+				elements[i] = ToNode(member.Value)
+			}
+			return parser.NewCurlyListNode(name, elements)
+		}
+		return parser.NewCurlyKeyValueNode(name, memberNodes(t.Members))
+	case *ObjectShapeType:
+		return parser.NewCurlyKeyValueNode("object", memberNodes(t.Properties))
+	case *ObjectType:
+		if t.Class == "" {
+			return parser.NewSimpleNode("object")
+		}
+		if len(t.TypeArguments) == 0 {
+			return parser.NewSimpleNode(t.Class)
+		}
+		arguments := nodeList(t.TypeArguments)
+		for i, argument := range arguments {
+			switch variance := t.variance(i); variance {
+			case parser.Bivariant:
+				arguments[i] = parser.NewWildcardNode()
+			case parser.Covariant, parser.Contravariant:
+				arguments[i] = parser.NewVarianceNode(variance, argument)
+			}
+		}
+		return parser.NewGenericNode(t.Class, arguments)
+	case *EnumCaseType:
+		return parser.NewConstFetchNode(t.Enum, t.Case)
+	case *CallableType:
+		if t.ReturnType == nil {
+			return parser.NewSimpleNode(t.Keyword())
+		}
+		parameters := make([]*parser.ParamNode, len(t.Parameters))
+		for i, parameter := range t.Parameters {
+			parameters[i] = &parser.ParamNode{
+				Type:     ToNode(parameter.Type),
+				Optional: parameter.Optional,
+				Variadic: parameter.Variadic,
+				ByRef:    parameter.ByRef,
+				Name:     parameter.Name,
+			}
+		}
+		return &parser.CallableNode{Name: t.Keyword(), Templates: t.Templates, ReturnType: ToNode(t.ReturnType), Parameters: parameters}
+	case *TemplateType:
+		return parser.NewSimpleNode(t.Name)
+	case *UnionType:
+		return &parser.UnionNode{Elements: nodeList(t.Types)}
+	case *IntersectionType:
+		return &parser.IntersectionNode{Elements: nodeList(t.Types)}
+	}
+	return parser.NewSimpleNode(t.String())
+}
+
+func intBoundNode(bound *int, unbounded string) parser.Node {
+	if bound == nil {
+		return parser.NewSimpleNode(unbounded)
+	}
+	return parser.NewIntLiteralNode(*bound)
+}
+
+func nodeList(types []Type) []parser.Node {
+	nodes := make([]parser.Node, len(types))
+	for i, t := range types {
+		nodes[i] = ToNode(t)
+	}
+	return nodes
+}
+
+func memberNodes(members []*ShapeMember) []*parser.MemberNode {
+	nodes := make([]*parser.MemberNode, len(members))
+	for i, member := range members {
+		nodes[i] = &parser.MemberNode{Key: member.Key, KeyKind: keyKind(member.Key), Value: ToNode(member.Value), Optional: member.Optional}
+	}
+	return nodes
+}
+
+// keyKind returns how a shape key is written: integer keys bare, every other
+// key as a string, which is quoted when needed.
+func keyKind(key string) parser.KeyKind {
+	if _, ok := shapeKeyType(key).(*IntLiteralType); ok {
+		return parser.IntKey
+	}
+	return parser.StringKey
+}
+
+func formatKey(key string) string {
+	return parser.FormatKey(key, keyKind(key))
+}
+
+func hasOptionalMember(members []*ShapeMember) bool {
+	for _, member := range members {
+		if member.Optional {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestToNode(t *testing.T) {
+	nodes := []parser.Node{
+		parser.NewSimpleNode("string"),
+		parser.NewSimpleNode("non-negative-int"),
+		parser.NewGenericNode("int", []parser.Node{parser.NewIntLiteralNode(1), parser.NewIntLiteralNode(5)}),
+		parser.NewGenericNode("int", []parser.Node{parser.NewSimpleNode("min"), parser.NewIntLiteralNode(5)}),
+		parser.NewGenericNode("list", []parser.Node{parser.NewSimpleNode("int")}),
+		parser.NewGenericNode("non-empty-array", []parser.Node{parser.NewSimpleNode("string"), parser.NewSimpleNode("int")}),
+		parser.NewGenericNode("class-string", []parser.Node{parser.NewSimpleNode("Foo")}),
+		parser.NewGenericNode("Collection", []parser.Node{parser.NewSimpleNode("int")}),
+		parser.NewGenericNode("Collection", []parser.Node{
+			parser.NewVarianceNode(parser.Contravariant, parser.NewSimpleNode("int")),
+			parser.NewWildcardNode(),
+		}),
+		parser.NewCurlyListNode("array", []parser.Node{parser.NewSimpleNode("int"), parser.NewSimpleNode("string")}),
+		parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+			parser.NewMember("foo", parser.NewSimpleNode("int")),
+			parser.NewOptionalMember("bar", parser.NewStringLiteralNode("x")),
+		}),
+		parser.NewCurlyKeyValueNode("object", []*parser.MemberNode{parser.NewMember("foo", parser.NewSimpleNode("int"))}),
+		parser.NewCallableNode(parser.NewSimpleNode("void"), []*parser.ParamNode{
+			parser.NewParam(parser.NewSimpleNode("int")),
+			parser.NewOptionalParam(parser.NewSimpleNode("string")),
+		}),
+		parser.NewSimpleNode("callable"),
+		parser.NewNamedCallableNode("pure-Closure", parser.NewSimpleNode("int"), []*parser.ParamNode{
+			{Type: parser.NewSimpleNode("string"), ByRef: true, Name: "out"},
+			parser.NewVariadicParam(parser.NewSimpleNode("int")),
+		}),
+		parser.NewSimpleNode("pure-callable"),
+		&parser.CallableNode{
+			Name:       "callable",
+			Templates:  []*parser.TemplateParam{parser.NewTemplateParam("T", parser.NewSimpleNode("int"))},
+			ReturnType: parser.NewGenericNode("list", []parser.Node{parser.NewSimpleNode("T")}),
+			Parameters: []*parser.ParamNode{parser.NewParam(parser.NewSimpleNode("T"))},
+		},
+		parser.NewUnionNode(parser.NewIntLiteralNode(1), parser.NewSimpleNode("null")),
+		parser.NewIntersectionNode(parser.NewSimpleNode("Foo"), parser.NewSimpleNode("Bar")),
+	}
+	for _, node := range nodes {
+		t.Run(node.String(), func(t *testing.T) {
+			resolved, err := types.Resolve(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := types.ToNode(resolved); got.String() != node.String() {
+				t.Errorf("ToNode(%s) = %s, want %s", resolved, got, node)
+			}
+		})
+	}
+}
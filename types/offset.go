@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+func (e *Env) resolveOffsetAccess(n *parser.OffsetAccessNode) (Type, error) {
+	t, err := e.Resolve(n.Type)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := e.Resolve(n.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return e.offsetType(t, offset)
+}
+
+func (e *Env) offsetType(t Type, offset Type) (Type, error) {
+	if u, ok := offset.(*UnionType); ok {
+		return e.mapUnion(u, func(offset Type) (Type, error) { return e.offsetType(t, offset) })
+	}
+	if key, ok := offsetKey(offset); ok {
+		offset = shapeKeyType(key)
+	}
+	switch t := t.(type) {
+	case *ShapeType:
+		return e.shapeOffset(t, offset)
+	case *ArrayType:
+		if !e.IsSubtype(offset, t.Key) {
+			return nil, fmt.Errorf("offset %s is not a valid key of %s", offset, t)
+		}
+		return t.Value, nil
+	case *UnionType:
+		return e.mapUnion(t, func(t Type) (Type, error) { return e.offsetType(t, offset) })
+	}
+	return nil, fmt.Errorf("cannot access offset %s of %s", offset, t)
+}
+
+func (e *Env) shapeOffset(shape *ShapeType, offset Type) (Type, error) {
+	var values []Type
+	covered := false
+	for _, member := range shape.Members {
+		if !e.IsSubtype(shapeKeyType(member.Key), offset) {
+			continue
+		}
+		covered = covered || e.IsSubtype(offset, shapeKeyType(member.Key))
+		values = append(values, member.Value)
+		if member.Optional {
+			values = append(values, NewNullType())
+		}
+	}
+	if key, value := shape.extra(); !shape.Sealed && !covered {
+		if _, never := e.Meet(offset, key).(*NeverType); !never {
+			values = append(values, value, NewNullType())
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("offset %s does not exist on %s", offset, shape)
+	}
+	return e.Simplify(union(values)), nil
+}
+
+func offsetKey(offset Type) (string, bool) {
+	switch offset := offset.(type) {
+	case *StringLiteralType:
+		return offset.Value, true
+	case *IntLiteralType:
+		return strconv.Itoa(offset.Value), true
+	}
+	return "", false
+}
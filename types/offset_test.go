@@ -0,0 +1,81 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestEnv_Resolve_OffsetAccess(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"array{foo: int, bar: string}['foo']", "int"},
+		{"array{foo: int, bar?: string}['bar']", "string | null"},
+		{"array{foo: int, bar: string}['foo' | 'bar']", "int | string"},
+		{"array{int, string}[1]", "string"},
+		{"array{int, string}['1']", "string"},
+		{"array{a: int, 0: string}[string]", "int"},
+		{"array{a: array{b: bool}}['a']['b']", "bool"},
+		{"array<string, int>['foo']", "int"},
+		{"list<Foo>[0]", "Foo"},
+		{"(array{a: int} | array{a: string})['a']", "int | string"},
+		{"Config::DEFAULTS['port']", "80"},
+		{"Config::DEFAULTS[key-of<Config::DEFAULTS>]", "\"localhost\" | 80"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := envResolve(t, env, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_Resolve_OffsetAccessErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"array{foo: int}['bar']", "offset \"bar\" does not exist on array{foo: int}"},
+		{"list<int>['foo']", "offset \"foo\" is not a valid key of list<int>"},
+		{"int['foo']", "cannot access offset \"foo\" of int"},
+	}
+	env := testEnv(t)
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			node, err := parser.Parse(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = env.Resolve(node)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("Resolve(%s) error = %v, want %s", test.src, err, test.want)
+			}
+		})
+	}
+}
+
+func TestSubstitute_OffsetAccess(t *testing.T) {
+	node, err := parser.Parse("T['id']")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := []*parser.TemplateParam{parser.NewTemplateParam("T", nil)}
+	substituted, err := types.Substitute(node, params, map[string]parser.Node{
+		"T": parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{parser.NewMember("id", parser.NewSimpleNode("int"))}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := types.Resolve(substituted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resolved.String(); got != "int" {
+		t.Errorf("Resolve(%s) = %s, want int", substituted, got)
+	}
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+type Reason struct {
+	Message string
+	Node    parser.Node
+	Loc     parser.Span
+	Reasons []*Reason
+}
+
+func newReason(message string, reasons ...*Reason) *Reason {
+	return &Reason{Message: message, Reasons: reasons}
+}
+
+func newMemberReason(member *ShapeMember, message string, reasons ...*Reason) *Reason {
+	reason := newReason(message, reasons...)
+	if member.Node != nil {
+		reason.Node = member.Node
+		reason.Loc = member.Node.Loc
+	}
+	return reason
+}
+
+func newParamReason(param *CallableParam, message string, reasons ...*Reason) *Reason {
+	reason := newReason(message, reasons...)
+	if param.Node != nil {
+		reason.Node = param.Node
+		reason.Loc = param.Node.Loc
+	}
+	return reason
+}
+
+func (r *Reason) HasLocation() bool {
+	return r.Loc.Start.Line > 0
+}
+
+func (r *Reason) String() string {
+	var b strings.Builder
+	r.render(&b, 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (r *Reason) render(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(r.Message)
+	if r.HasLocation() {
+		fmt.Fprintf(b, " (at %s)", r.Loc)
+	}
+	b.WriteString("\n")
+	for _, reason := range r.Reasons {
+		reason.render(b, depth+1)
+	}
+}
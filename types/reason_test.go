@@ -0,0 +1,59 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestReason_Location(t *testing.T) {
+	source := parser.NewMember("bar", parser.NewSimpleNode("int"))
+	source.Optional = true
+	source.Loc = parser.NewSpanFromInts(1, 7, 1, 15)
+	sub, err := types.Resolve(parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{source}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	super, err := types.Resolve(parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+		parser.NewMember("bar", parser.NewSimpleNode("int")),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reason := types.Explain(sub, super)
+	if reason == nil {
+		t.Fatalf("expected %s not to be a subtype of %s", sub, super)
+	}
+	member := reason.Reasons[0]
+	if member.Node != source {
+		t.Errorf("expected reason to point at %s, got %v", source, member.Node)
+	}
+	want := "array{bar?: int} is not a subtype of array{bar: int}\n" +
+		"  member bar is required in target but optional in source (at 1:7-1:15)"
+	if got := reason.String(); got != want {
+		t.Errorf("Reason.String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReason_ParamLocation(t *testing.T) {
+	source := parser.NewParam(parser.NewSimpleNode("positive-int"))
+	source.Loc = parser.NewSpanFromInts(2, 10, 2, 21)
+	sub, err := types.Resolve(parser.NewCallableNode(parser.NewSimpleNode("void"), []*parser.ParamNode{source}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	super := types.NewCallableType(types.NewVoidType(), []*types.CallableParam{types.NewCallableParam(types.NewIntType())})
+
+	reason := types.Explain(sub, super)
+	if reason == nil {
+		t.Fatalf("expected %s not to be a subtype of %s", sub, super)
+	}
+	want := "callable(positive-int): void is not a subtype of callable(int): void\n" +
+		"  parameter 1 of callable has an incompatible type (at 2:10-2:21)\n" +
+		"    int is not a subtype of positive-int"
+	if got := reason.String(); got != want {
+		t.Errorf("Reason.String() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,237 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+// ClassRegistry holds the declared classes and interfaces. Lookups ignore
+// case and a leading backslash, like class names in PHP.
+type ClassRegistry struct {
+	classes map[string]*Class
+}
+
+func NewClassRegistry(classes ...*Class) *ClassRegistry {
+	r := &ClassRegistry{classes: make(map[string]*Class, len(classes))}
+	r.Add(classes...)
+	return r
+}
+
+// Add registers classes, replacing earlier declarations of the same name.
+func (r *ClassRegistry) Add(classes ...*Class) {
+	for _, c := range classes {
+		r.classes[classKey(c.Name)] = c
+	}
+}
+
+func (r *ClassRegistry) Class(name string) *Class {
+	if r == nil {
+		return nil
+	}
+	return r.classes[classKey(name)]
+}
+
+// Classes returns all registered classes in no particular order.
+func (r *ClassRegistry) Classes() []*Class {
+	if r == nil {
+		return nil
+	}
+	classes := make([]*Class, 0, len(r.classes))
+	for _, c := range r.classes {
+		classes = append(classes, c)
+	}
+	return classes
+}
+
+// IsSubclassOf reports whether class is ancestor or extends or implements it,
+// directly or through its supertypes.
+func (r *ClassRegistry) IsSubclassOf(class, ancestor string) bool {
+	return r.isSubclassOf(class, ancestor, map[string]bool{})
+}
+
+func (r *ClassRegistry) isSubclassOf(class, ancestor string, seen map[string]bool) bool {
+	if sameClass(class, ancestor) {
+		return true
+	}
+	if seen[classKey(class)] {
+		return false
+	}
+	seen[classKey(class)] = true
+	c := r.Class(class)
+	if c == nil {
+		return false
+	}
+	for _, supertype := range c.supertypes() {
+		if n, ok := supertype.(*parser.IdentifierNode); ok && r.isSubclassOf(n.Name, ancestor, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+func classKey(name string) string {
+	return strings.ToLower(strings.TrimPrefix(name, "\\"))
+}
+
+type classFile struct {
+	Classes []*classJSON `json:"classes"`
+}
+
+type classJSON struct {
+	Name       string          `json:"name"`
+	Interface  bool            `json:"interface"`
+	Templates  []*templateJSON `json:"templates"`
+	Extends    stringList      `json:"extends"`
+	Implements []string        `json:"implements"`
+	Properties []*propertyJSON `json:"properties"`
+}
+
+type templateJSON struct {
+	Name     string `json:"name"`
+	Bound    string `json:"bound"`
+	Default  string `json:"default"`
+	Variance string `json:"variance"`
+}
+
+type propertyJSON struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// stringList accepts a single string as well as a list of strings, so that a
+// class can extend one class and an interface several interfaces.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// LoadClassRegistry reads a registry from the JSON file at path.
+func LoadClassRegistry(path string) (*ClassRegistry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadClassRegistry(f)
+}
+
+// ReadClassRegistry reads a registry in JSON format, for example
+//
+//	{"classes": [
+//	  {"name": "Collection", "interface": true, "templates": [{"name": "T", "variance": "covariant"}]},
+//	  {"name": "IntList", "extends": "AbstractList<int>", "implements": ["Countable"],
+//	   "properties": [{"name": "first", "type": "int"}]}
+//	]}
+//
+// Supertypes, bounds, defaults and property types are parsed as types.
+func ReadClassRegistry(r io.Reader) (*ClassRegistry, error) {
+	var file classFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("cannot read class registry: %w", err)
+	}
+	registry := NewClassRegistry()
+	for _, c := range file.Classes {
+		class, err := c.class()
+		if err != nil {
+			return nil, err
+		}
+		registry.Add(class)
+	}
+	return registry, nil
+}
+
+func (c *classJSON) class() (*Class, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("class without a name in class registry")
+	}
+	class := &Class{Name: c.Name, Interface: c.Interface}
+	for _, t := range c.Templates {
+		template, err := t.template()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		class.Templates = append(class.Templates, template)
+	}
+	extends, err := parseAll(c.Extends)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.Name, err)
+	}
+	implements, err := parseAll(c.Implements)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.Name, err)
+	}
+	switch {
+	case c.Interface && len(implements) > 0:
+		return nil, fmt.Errorf("interface %s cannot implement interfaces, use extends", c.Name)
+	case c.Interface:
+		class.Interfaces = extends
+	case len(extends) > 1:
+		return nil, fmt.Errorf("class %s can only extend one class", c.Name)
+	case len(extends) == 1:
+		class.Parent = extends[0]
+		fallthrough
+	default:
+		class.Interfaces = implements
+	}
+	for _, p := range c.Properties {
+		t, err := parser.Parse(p.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s::$%s: %w", c.Name, p.Name, err)
+		}
+		class.Properties = append(class.Properties, NewProperty(p.Name, t))
+	}
+	return class, nil
+}
+
+func (t *templateJSON) template() (*parser.TemplateParam, error) {
+	template := &parser.TemplateParam{Name: t.Name}
+	switch t.Variance {
+	case "", "invariant":
+	case "covariant":
+		template.Variance = parser.Covariant
+	case "contravariant":
+		template.Variance = parser.Contravariant
+	default:
+		return nil, fmt.Errorf("unknown variance %q of template %s", t.Variance, t.Name)
+	}
+	var err error
+	if t.Bound != "" {
+		if template.Bound, err = parser.Parse(t.Bound); err != nil {
+			return nil, err
+		}
+	}
+	if t.Default != "" {
+		if template.Default, err = parser.Parse(t.Default); err != nil {
+			return nil, err
+		}
+	}
+	return template, nil
+}
+
+func parseAll(sources []string) ([]parser.Node, error) {
+	nodes := make([]parser.Node, len(sources))
+	for i, src := range sources {
+		node, err := parser.Parse(src)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
+	}
+	return nodes, nil
+}
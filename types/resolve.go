@@ -0,0 +1,409 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+var simpleTypes = map[string]func() Type{
+	"mixed":            NewMixedType,
+	"never":            NewNeverType,
+	"never-return":     NewNeverType,
+	"never-returns":    NewNeverType,
+	"no-return":        NewNeverType,
+	"void":             NewVoidType,
+	"null":             NewNullType,
+	"resource":         NewResourceType,
+	"bool":             NewBoolType,
+	"boolean":          NewBoolType,
+	"true":             func() Type { return NewBoolLiteralType(true) },
+	"false":            func() Type { return NewBoolLiteralType(false) },
+	"integer":          NewIntType,
+	"positive-int":     func() Type { return NewIntRangeType(intPtr(1), nil) },
+	"non-negative-int": func() Type { return NewIntRangeType(intPtr(0), nil) },
+	"negative-int":     func() Type { return NewIntRangeType(nil, intPtr(-1)) },
+	"non-positive-int": func() Type { return NewIntRangeType(nil, intPtr(0)) },
+	"float":            NewFloatType,
+	"double":           NewFloatType,
+	"string":           NewStringType,
+	"non-empty-string": NewNonEmptyStringType,
+	"numeric-string":   NewNumericStringType,
+	"array-key":        NewArrayKeyType,
+	"scalar": func() Type {
+		return NewUnionType(NewBoolType(), NewIntType(), NewFloatType(), NewStringType())
+	},
+	"numeric": func() Type {
+		return NewUnionType(NewIntType(), NewFloatType(), NewNumericStringType())
+	},
+	"object":   func() Type { return NewObjectType("") },
+	"callable": NewAnyCallableType,
+	"pure-callable": func() Type {
+		return &CallableType{Pure: true}
+	},
+	"pure-closure": func() Type {
+		return &CallableType{Closure: true, Pure: true}
+	},
+}
+
+func Resolve(node parser.Node) (Type, error) {
+	return (&Env{}).Resolve(node)
+}
+
+func (e *Env) Resolve(node parser.Node) (Type, error) {
+	switch n := node.(type) {
+	case *parser.IdentifierNode:
+		return e.resolveIdentifier(n)
+	case *parser.CurlyListNode:
+		return e.resolveCurlyList(n)
+	case *parser.CurlyKeyValueNode:
+		return e.resolveCurlyKeyValue(n)
+	case *parser.CallableNode:
+		return e.resolveCallable(n)
+	case *parser.StringLiteralNode:
+		return NewStringLiteralType(n.Value), nil
+	case *parser.IntLiteralNode:
+		return NewIntLiteralType(n.Value), nil
+	case *parser.UnionNode:
+		types, err := e.resolveList(n.Elements)
+		if err != nil {
+			return nil, err
+		}
+		return &UnionType{Types: flattenUnion(types)}, nil
+	case *parser.IntersectionNode:
+		types, err := e.resolveList(n.Elements)
+		if err != nil {
+			return nil, err
+		}
+		return &IntersectionType{Types: flattenIntersection(types)}, nil
+	case *parser.ConstFetchNode:
+		return e.resolveConstFetch(n)
+	case *parser.OffsetAccessNode:
+		return e.resolveOffsetAccess(n)
+	case *parser.ConditionalNode:
+		return e.resolveConditional(n)
+	case *parser.VariableNode:
+		return nil, fmt.Errorf("%s is only allowed as the subject of a conditional type", node)
+	case *parser.VarianceNode, *parser.WildcardNode:
+		return nil, fmt.Errorf("%s is only allowed as a type argument of a class", node)
+	}
+	return nil, fmt.Errorf("unsupported node %s", node)
+}
+
+func (e *Env) resolveList(nodes []parser.Node) ([]Type, error) {
+	types := make([]Type, len(nodes))
+	for i, node := range nodes {
+		t, err := e.Resolve(node)
+		if err != nil {
+			return nil, err
+		}
+		types[i] = t
+	}
+	return types, nil
+}
+
+func flattenUnion(types []Type) []Type {
+	var flat []Type
+	for _, t := range types {
+		if union, ok := t.(*UnionType); ok {
+			flat = append(flat, flattenUnion(union.Types)...)
+			continue
+		}
+		flat = append(flat, t)
+	}
+	return flat
+}
+
+func flattenIntersection(types []Type) []Type {
+	var flat []Type
+	for _, t := range types {
+		if intersection, ok := t.(*IntersectionType); ok {
+			flat = append(flat, flattenIntersection(intersection.Types)...)
+			continue
+		}
+		flat = append(flat, t)
+	}
+	return flat
+}
+
+func (e *Env) resolveIdentifier(n *parser.IdentifierNode) (Type, error) {
+	name := strings.ToLower(n.Name)
+	if name == "int" {
+		return resolveInt(n)
+	}
+	if constructor, ok := simpleTypes[name]; ok {
+		if len(n.TypeArguments) > 0 {
+			return nil, fmt.Errorf("%s does not accept type arguments", n.Name)
+		}
+		return constructor(), nil
+	}
+	if !isBuiltinGeneric(name) {
+		return e.resolveObject(n)
+	}
+	args, err := e.resolveList(n.TypeArguments)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "array", "non-empty-array":
+		if err := checkArity(n, 0, 2); err != nil {
+			return nil, err
+		}
+		key, value := arrayArguments(args)
+		if !isArrayKey(key) {
+			return nil, fmt.Errorf("%s is not a valid array key type in %s", key, n)
+		}
+		if name == "non-empty-array" {
+			return NewNonEmptyArrayType(key, value), nil
+		}
+		return NewArrayType(key, value), nil
+	case "list", "non-empty-list":
+		if err := checkArity(n, 0, 1); err != nil {
+			return nil, err
+		}
+		value := NewMixedType()
+		if len(args) == 1 {
+			value = args[0]
+		}
+		if name == "non-empty-list" {
+			return NewNonEmptyListType(value), nil
+		}
+		return NewListType(value), nil
+	case "iterable":
+		if err := checkArity(n, 0, 2); err != nil {
+			return nil, err
+		}
+		key, value := arrayArguments(args)
+		if len(args) < 2 {
+			key = NewMixedType()
+		}
+		return NewIterableType(key, value), nil
+	case "key-of", "value-of":
+		if err := checkArity(n, 1, 1); err != nil {
+			return nil, err
+		}
+		if name == "key-of" {
+			return e.keyOf(args[0])
+		}
+		return e.valueOf(args[0])
+	case "class-string":
+		if err := checkArity(n, 0, 1); err != nil {
+			return nil, err
+		}
+		if len(args) == 0 {
+			return NewClassStringType(nil), nil
+		}
+		if _, ok := args[0].(*ObjectType); !ok {
+			return nil, fmt.Errorf("%s is not a valid class type in %s", args[0], n)
+		}
+		return NewClassStringType(args[0]), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", n)
+}
+
+func isBuiltinGeneric(name string) bool {
+	switch name {
+	case "array", "non-empty-array", "list", "non-empty-list", "iterable", "class-string", "key-of", "value-of":
+		return true
+	}
+	return false
+}
+
+func (e *Env) resolveObject(n *parser.IdentifierNode) (Type, error) {
+	object := &ObjectType{Class: n.Name}
+	for i, argument := range n.TypeArguments {
+		variance := parser.Invariant
+		switch a := argument.(type) {
+		case *parser.WildcardNode:
+			variance = parser.Bivariant
+			argument = parser.NewSimpleNode("mixed")
+		case *parser.VarianceNode:
+			variance = a.Variance
+			argument = a.Type
+		}
+		t, err := e.Resolve(argument)
+		if err != nil {
+			return nil, err
+		}
+		object.TypeArguments = append(object.TypeArguments, t)
+		if variance != parser.Invariant {
+			if object.Variances == nil {
+				object.Variances = make([]parser.Variance, len(n.TypeArguments))
+			}
+			object.Variances[i] = variance
+		}
+	}
+	return object, nil
+}
+
+func checkArity(n *parser.IdentifierNode, min, max int) error {
+	count := len(n.TypeArguments)
+	if count >= min && count <= max {
+		return nil
+	}
+	if min == max {
+		return fmt.Errorf("%s expects %d type arguments, got %d in %s", n.Name, min, count, n)
+	}
+	return fmt.Errorf("%s expects %d to %d type arguments, got %d in %s", n.Name, min, max, count, n)
+}
+
+func arrayArguments(args []Type) (Type, Type) {
+	switch len(args) {
+	case 1:
+		return NewArrayKeyType(), args[0]
+	case 2:
+		return args[0], args[1]
+	}
+	return NewArrayKeyType(), NewMixedType()
+}
+
+func isArrayKey(t Type) bool {
+	switch t := t.(type) {
+	case *IntType, *IntLiteralType, *StringType, *StringLiteralType, *ClassStringType:
+		return true
+	case *UnionType:
+		for _, element := range t.Types {
+			if !isArrayKey(element) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func resolveInt(n *parser.IdentifierNode) (Type, error) {
+	if err := checkArity(n, 0, 2); err != nil {
+		return nil, err
+	}
+	switch len(n.TypeArguments) {
+	case 0:
+		return NewIntType(), nil
+	case 1:
+		return nil, fmt.Errorf("int expects 0 or 2 type arguments, got 1 in %s", n)
+	}
+	min, err := intRangeBound(n.TypeArguments[0], "min")
+	if err != nil {
+		return nil, err
+	}
+	max, err := intRangeBound(n.TypeArguments[1], "max")
+	if err != nil {
+		return nil, err
+	}
+	if min != nil && max != nil && *min > *max {
+		return nil, fmt.Errorf("int range minimum %d is greater than maximum %d", *min, *max)
+	}
+	return NewIntRangeType(min, max), nil
+}
+
+func intRangeBound(node parser.Node, unbounded string) (*int, error) {
+	switch n := node.(type) {
+	case *parser.IntLiteralNode:
+		return intPtr(n.Value), nil
+	case *parser.IdentifierNode:
+		if n.Name == unbounded && len(n.TypeArguments) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid int range bound %s, expected an integer or %s", node, unbounded)
+}
+
+func (e *Env) resolveCurlyList(n *parser.CurlyListNode) (Type, error) {
+	if n.Name != "array" && n.Name != "list" {
+		return nil, fmt.Errorf("%s does not support list shapes", n.Name)
+	}
+	return e.resolveCurlyKeyValue(n.Keyed())
+}
+
+func (e *Env) resolveCurlyKeyValue(n *parser.CurlyKeyValueNode) (Type, error) {
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	members := make([]*ShapeMember, len(n.Members))
+	seen := make(map[string]bool, len(n.Members))
+	for i, member := range n.Members {
+		if seen[member.Key] {
+			return nil, fmt.Errorf("duplicate key %s in %s", formatKey(member.Key), n)
+		}
+		seen[member.Key] = true
+		value, err := e.Resolve(member.Value)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = &ShapeMember{Key: member.Key, Value: value, Optional: member.Optional, Node: member}
+	}
+	switch n.Name {
+	case "array", "list":
+		if n.Sealed {
+			return &ShapeType{Members: members, Sealed: true, List: n.Name == "list"}, nil
+		}
+		return e.resolveUnsealed(n, members)
+	case "object":
+		return NewObjectShapeType(members), nil
+	}
+	return nil, fmt.Errorf("%s does not support shapes", n.Name)
+}
+
+func (e *Env) resolveUnsealed(n *parser.CurlyKeyValueNode, members []*ShapeMember) (Type, error) {
+	shape := &ShapeType{Members: members, List: n.Name == "list"}
+	if n.ExtraKey != nil {
+		key, err := e.Resolve(n.ExtraKey)
+		if err != nil {
+			return nil, err
+		}
+		if !isArrayKey(key) {
+			return nil, fmt.Errorf("%s is not a valid array key type in %s", key, n)
+		}
+		shape.ExtraKey = key
+	}
+	if n.ExtraValue != nil {
+		value, err := e.Resolve(n.ExtraValue)
+		if err != nil {
+			return nil, err
+		}
+		shape.ExtraValue = value
+	}
+	return shape, nil
+}
+
+func (e *Env) resolveCallable(n *parser.CallableNode) (Type, error) {
+	scoped := e
+	if len(n.Templates) > 0 {
+		scoped = e.withCallableTemplates(n.Templates)
+	}
+	returnType, err := scoped.Resolve(n.ReturnType)
+	if err != nil {
+		return nil, err
+	}
+	parameters := make([]*CallableParam, len(n.Parameters))
+	for i, parameter := range n.Parameters {
+		t, err := scoped.Resolve(parameter.Type)
+		if err != nil {
+			return nil, err
+		}
+		parameters[i] = &CallableParam{
+			Type:     t,
+			Optional: parameter.Optional,
+			Variadic: parameter.Variadic,
+			ByRef:    parameter.ByRef,
+			Name:     parameter.Name,
+			Node:     parameter,
+		}
+	}
+	callable := &CallableType{Templates: n.Templates, Parameters: parameters, ReturnType: returnType}
+	callable.Closure, callable.Pure = callableKind(n.Name)
+	if len(n.Templates) == 0 {
+		return callable, nil
+	}
+	templates, err := e.resolveTemplates(n.Templates)
+	if err != nil {
+		return nil, err
+	}
+	return replaceTemplates(callable, templates), nil
+}
+
+func callableKind(name string) (closure bool, pure bool) {
+	name = strings.ToLower(strings.TrimPrefix(name, "\\"))
+	return strings.HasSuffix(name, "closure"), strings.HasPrefix(name, "pure-")
+}
@@ -0,0 +1,148 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		node parser.Node
+		want string
+	}{
+		{parser.NewSimpleNode("string"), "string"},
+		{parser.NewSimpleNode("boolean"), "bool"},
+		{parser.NewSimpleNode("positive-int"), "positive-int"},
+		{parser.NewSimpleNode("array-key"), "int | string"},
+		{parser.NewSimpleNode("array"), "array<int | string, mixed>"},
+		{parser.NewSimpleNode("list"), "list<mixed>"},
+		{parser.NewSimpleNode("callable"), "callable"},
+		{parser.NewSimpleNode("object"), "object"},
+		{parser.NewGenericNode("int", []parser.Node{
+			parser.NewIntLiteralNode(0),
+			parser.NewSimpleNode("max"),
+		}), "non-negative-int"},
+		{parser.NewGenericNode("int", []parser.Node{
+			parser.NewSimpleNode("min"),
+			parser.NewIntLiteralNode(10),
+		}), "int<min, 10>"},
+		{parser.NewGenericNode("array", []parser.Node{parser.NewSimpleNode("bool")}), "array<int | string, bool>"},
+		{parser.NewGenericNode("non-empty-array", []parser.Node{
+			parser.NewSimpleNode("int"),
+			parser.NewSimpleNode("string"),
+		}), "non-empty-array<int, string>"},
+		{parser.NewGenericNode("list", []parser.Node{parser.NewSimpleNode("positive-int")}), "list<positive-int>"},
+		{parser.NewGenericNode("iterable", []parser.Node{parser.NewSimpleNode("int")}), "iterable<mixed, int>"},
+		{parser.NewGenericNode("class-string", []parser.Node{parser.NewSimpleNode("Foo")}), "class-string<Foo>"},
+		{parser.NewGenericNode("Collection", []parser.Node{parser.NewSimpleNode("int")}), "Collection<int>"},
+		{parser.NewCurlyListNode("array", []parser.Node{
+			parser.NewSimpleNode("int"),
+			parser.NewSimpleNode("string"),
+		}), "array{int, string}"},
+		{parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+			parser.NewMember("foo", parser.NewSimpleNode("int")),
+			parser.NewOptionalMember("bar", parser.NewSimpleNode("string")),
+		}), "array{foo: int, bar?: string}"},
+		{parser.NewCurlyKeyValueNode("object", []*parser.MemberNode{
+			parser.NewMember("foo", parser.NewSimpleNode("int")),
+		}), "object{foo: int}"},
+		{parser.NewCallableNode(parser.NewSimpleNode("void"), []*parser.ParamNode{
+			parser.NewParam(parser.NewSimpleNode("int")),
+			parser.NewOptionalParam(parser.NewSimpleNode("string")),
+		}), "callable(int, string=): void"},
+		{parser.NewStringLiteralNode("foo"), "\"foo\""},
+		{parser.NewIntLiteralNode(-3), "-3"},
+		{parser.NewUnionNode(
+			parser.NewSimpleNode("int"),
+			parser.NewUnionNode(parser.NewSimpleNode("string"), parser.NewSimpleNode("null")),
+		), "int | string | null"},
+		{parser.NewIntersectionNode(parser.NewSimpleNode("Foo"), parser.NewSimpleNode("Bar")), "Foo & Bar"},
+		{parser.NewGenericNode("Collection", []parser.Node{
+			parser.NewVarianceNode(parser.Covariant, parser.NewSimpleNode("Foo")),
+			parser.NewWildcardNode(),
+			parser.NewSimpleNode("int"),
+		}), "Collection<covariant Foo, *, int>"},
+	}
+	for _, test := range tests {
+		t.Run(test.node.String(), func(t *testing.T) {
+			got, err := types.Resolve(test.node)
+			if err != nil {
+				t.Fatalf("Resolve(%s) returned error: %v", test.node, err)
+			}
+			if got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.node, got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := []struct {
+		node parser.Node
+		want string
+	}{
+		{
+			parser.NewGenericNode("list", []parser.Node{parser.NewSimpleNode("int"), parser.NewSimpleNode("string")}),
+			"list expects 0 to 1 type arguments, got 2 in list<int, string>",
+		},
+		{
+			parser.NewGenericNode("string", []parser.Node{parser.NewSimpleNode("int")}),
+			"string does not accept type arguments",
+		},
+		{
+			parser.NewGenericNode("array", []parser.Node{parser.NewSimpleNode("float"), parser.NewSimpleNode("int")}),
+			"float is not a valid array key type in array<float, int>",
+		},
+		{
+			parser.NewGenericNode("int", []parser.Node{parser.NewIntLiteralNode(5)}),
+			"int expects 0 or 2 type arguments, got 1 in int<5>",
+		},
+		{
+			parser.NewGenericNode("int", []parser.Node{parser.NewIntLiteralNode(5), parser.NewIntLiteralNode(1)}),
+			"int range minimum 5 is greater than maximum 1",
+		},
+		{
+			parser.NewGenericNode("int", []parser.Node{parser.NewSimpleNode("max"), parser.NewIntLiteralNode(1)}),
+			"invalid int range bound max, expected an integer or min",
+		},
+		{
+			parser.NewGenericNode("class-string", []parser.Node{parser.NewSimpleNode("int")}),
+			"int is not a valid class type in class-string<int>",
+		},
+		{
+			parser.NewCurlyKeyValueNode("array", []*parser.MemberNode{
+				parser.NewMember("foo", parser.NewSimpleNode("int")),
+				parser.NewMember("foo", parser.NewSimpleNode("string")),
+			}),
+			"duplicate key foo in array{foo: int, foo: string}",
+		},
+		{
+			parser.NewCurlyListNode("Foo", nil),
+			"Foo does not support list shapes",
+		},
+		{
+			parser.NewGenericNode("list", []parser.Node{parser.NewWildcardNode()}),
+			"* is only allowed as a type argument of a class",
+		},
+		{
+			parser.NewGenericNode("array", []parser.Node{parser.NewGenericNode("list", []parser.Node{
+				parser.NewSimpleNode("int"),
+				parser.NewSimpleNode("int"),
+			})}),
+			"list expects 0 to 1 type arguments, got 2 in list<int, int>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.node.String(), func(t *testing.T) {
+			_, err := types.Resolve(test.node)
+			if err == nil {
+				t.Fatalf("Resolve(%s) returned no error", test.node)
+			}
+			if err.Error() != test.want {
+				t.Errorf("Resolve(%s) error = %q, want %q", test.node, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestResolve_ShapeKeys(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"array{'foo-bar': int, \"with space\": bool}", "array{foo-bar: int, \"with space\": bool}"},
+		{"array{'0': int, \"1\": string}", "array{int, string}"},
+		{"array{'01': int}", "array{\"01\": int}"},
+		{"key-of<array{'0': int, 'a b': string}>", "0 | \"a b\""},
+		{"array{'0': int, 'a b': string}[0]", "int"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := mustResolve(t, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolve_ListShape(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"list{int, string}", "list{int, string}"},
+		{"list{0: int, 1?: string}", "list{0: int, 1?: string}"},
+		{"list{int, ...<string>}", "list{int, ...<string>}"},
+		{"key-of<list{int, ...<string>}>", "non-negative-int"},
+		{"list{int, ...<string>}[5]", "string | null"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := mustResolve(t, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsSubtype_ListShape(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"list{int, string}", "array{int, string}", true},
+		{"array{int, string}", "list{int, string}", true},
+		{"array{1: int}", "list{int, ...}", false},
+		{"list{int, ...<string>}", "list<int | string>", true},
+		{"list{int, ...<string>}", "list<int>", false},
+		{"array{int, ...<string>}", "list<int | string>", false},
+		{"list{int, string, string}", "list{int, ...<string>}", true},
+		{"list<int>", "list{0?: int, ...<int>}", true},
+		{"array<int>", "list{0?: int, ...<int>}", false},
+		{"list{int, ...}", "array{int, ...}", true},
+		{"array{int, ...}", "list{int, ...}", false},
+	}
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := mustResolve(t, test.sub), mustResolve(t, test.super)
+			if got := types.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolve_UnsealedShape(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"array{foo: int, ...}", "array{foo: int, ...}"},
+		{"array{...}", "array{...}"},
+		{"array{foo: int, ...<string, mixed>}", "array{foo: int, ...<string, mixed>}"},
+		{"array{foo: int, ...<bool>}", "array{foo: int, ...<bool>}"},
+		{"array{int, string, ...}", "array{int, string, ...}"},
+		{"key-of<array{foo: int, ...<int, bool>}>", "\"foo\" | int"},
+		{"value-of<array{foo: int, ...<int, bool>}>", "int | bool"},
+		{"array{foo: int, ...<string, bool>}['foo']", "int"},
+		{"array{foo: int, ...<string, bool>}['bar']", "bool | null"},
+		{"array{foo: int, ...<string, bool>}[string]", "int | bool | null"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			if got := mustResolve(t, test.src); got.String() != test.want {
+				t.Errorf("Resolve(%s) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolve_UnsealedShapeErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"array{0: int, '0': string}", "duplicate key 0 in array{0: int, \"0\": string}"},
+		{"array{foo: int, \"foo\": string}", "duplicate key foo in array{foo: int, foo: string}"},
+		{"array{'a b': int, 'a b'?: string}", "duplicate key \"a b\" in array{\"a b\": int, \"a b\"?: string}"},
+		{"array{foo: int, ...<float, int>}", "float is not a valid array key type in array{foo: int, ...<float, int>}"},
+		{"array{foo: int, ...<string, int>}['bar'] | array{...<int, int>}['x']", "offset \"x\" does not exist on array{...<int, int>}"},
+		{"object{foo: int, ...}", "object shapes cannot be unsealed in object{foo: int, ...}"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			node, err := parser.Parse(test.src)
+			if err == nil {
+				_, err = types.Resolve(node)
+			}
+			if err == nil || err.Error() != test.want {
+				t.Errorf("Resolve(%s) error = %v, want %s", test.src, err, test.want)
+			}
+		})
+	}
+}
+
+func TestIsSubtype_UnsealedShape(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"array{foo: int, bar: string}", "array{foo: int, ...}", true},
+		{"array{foo: int, bar: string}", "array{foo: int}", false},
+		{"array{foo: int, ...}", "array{foo: int}", false},
+		{"array{foo: int, ...}", "array{foo: int, ...}", true},
+		{"array{foo: int}", "array{foo: int, ...}", true},
+		{"array{foo: int, bar: string}", "array{foo: int, ...<string, string>}", true},
+		{"array{foo: int, bar: string}", "array{foo: int, ...<string, int>}", false},
+		{"array{foo: int, 0: string}", "array{foo: int, ...<string, string>}", false},
+		{"array{foo: int, ...<string, int>}", "array{foo: int, ...<array-key, mixed>}", true},
+		{"array{foo: int, ...<string, mixed>}", "array{foo: int, ...<string, int>}", false},
+		{"array{...<string, string>}", "array{foo?: int, ...}", false},
+		{"array{...<int, string>}", "array{foo?: int, ...}", true},
+		{"array{foo: int, ...<string, int>}", "array<string, int>", true},
+		{"array{foo: int, ...}", "array<string, int>", false},
+		{"array{0: int, ...}", "list<int>", false},
+		{"array<string, int>", "array{foo?: int, ...<string, int>}", true},
+		{"array<string, int>", "array{foo: int, ...}", false},
+		{"array<string, int>", "array{foo?: int}", false},
+		{"array{foo: int, ...}", "iterable<string, mixed>", false},
+	}
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := mustResolve(t, test.sub), mustResolve(t, test.super)
+			if got := types.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestJoinMeet_UnsealedShape(t *testing.T) {
+	tests := []struct {
+		a, b string
+		join string
+		meet string
+	}{
+		{
+			"array{foo: int, ...<string, bool>}", "array{bar: string}",
+			"array{foo?: int, bar?: string | bool, ...<string, bool>}",
+			"never",
+		},
+		{
+			"array{foo: int, ...<string, int>}", "array{bar: int, ...<string, int | string>}",
+			"array{foo?: int | string, bar?: int, ...<string, int | string>}",
+			"array{foo: int, bar: int, ...<string, int>}",
+		},
+		{
+			"array{foo: int, ...<string, int>}", "array<string, positive-int>",
+			"array<string, int>",
+			"array{foo: positive-int, ...<string, positive-int>}",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			a, b := mustResolve(t, test.a), mustResolve(t, test.b)
+			if got := types.Join(a, b); got.String() != test.join {
+				t.Errorf("Join(%s, %s) = %s, want %s", a, b, got, test.join)
+			}
+			if got := types.Meet(a, b); got.String() != test.meet {
+				t.Errorf("Meet(%s, %s) = %s, want %s", a, b, got, test.meet)
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package types
+
+import (
+	"sort"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+// Simplify is (&Env{}).Simplify.
+func Simplify(t Type) Type {
+	return (&Env{}).Simplify(t)
+}
+
+// Simplify removes redundant elements from unions and intersections,
+// recursively. Elements that are subtypes of other union elements are
+// absorbed, array shapes in intersections are merged and contradictory
+// intersections are reduced to never.
+func (e *Env) Simplify(t Type) Type {
+	switch t := t.(type) {
+	case *UnionType:
+		return e.simplifyUnion(t.Types)
+	case *IntersectionType:
+		return e.simplifyIntersection(t.Types)
+	}
+	return mapChildren(t, e.Simplify)
+}
+
+func SimplifyNode(node parser.Node) (parser.Node, error) {
+	t, err := Resolve(node)
+	if err != nil {
+		return nil, err
+	}
+	return ToNode(Simplify(t)), nil
+}
+
+// mapChildren returns a copy of t with f applied to each of its direct
+// children. Types without children are returned as-is.
+func mapChildren(t Type, f func(Type) Type) Type {
+	switch t := t.(type) {
+	case *ClassStringType:
+		if t.Class == nil {
+			return t
+		}
+		return &ClassStringType{Class: f(t.Class)}
+	case *ArrayType:
+		return &ArrayType{Key: f(t.Key), Value: f(t.Value), List: t.List, NonEmpty: t.NonEmpty}
+	case *IterableType:
+		return &IterableType{Key: f(t.Key), Value: f(t.Value)}
+	case *ShapeType:
+		shape := &ShapeType{Members: mapMembers(t.Members, f), Sealed: t.Sealed, List: t.List}
+		if t.ExtraKey != nil {
+			shape.ExtraKey = f(t.ExtraKey)
+		}
+		if t.ExtraValue != nil {
+			shape.ExtraValue = f(t.ExtraValue)
+		}
+		return shape
+	case *ObjectShapeType:
+		return &ObjectShapeType{Properties: mapMembers(t.Properties, f)}
+	case *ObjectType:
+		if len(t.TypeArguments) == 0 {
+			return t
+		}
+		return &ObjectType{Class: t.Class, TypeArguments: mapTypes(t.TypeArguments, f), Variances: t.Variances}
+	case *CallableType:
+		if t.ReturnType == nil {
+			return t
+		}
+		parameters := make([]*CallableParam, len(t.Parameters))
+		for i, parameter := range t.Parameters {
+			mapped := *parameter
+			mapped.Type = f(parameter.Type)
+			parameters[i] = &mapped
+		}
+		return &CallableType{
+			Templates:  t.Templates,
+			Parameters: parameters,
+			ReturnType: f(t.ReturnType),
+			Closure:    t.Closure,
+			Pure:       t.Pure,
+		}
+	case *UnionType:
+		return &UnionType{Types: mapTypes(t.Types, f)}
+	case *IntersectionType:
+		return &IntersectionType{Types: mapTypes(t.Types, f)}
+	}
+	return t
+}
+
+func mapMembers(members []*ShapeMember, f func(Type) Type) []*ShapeMember {
+	mapped := make([]*ShapeMember, len(members))
+	for i, member := range members {
+		mapped[i] = &ShapeMember{Key: member.Key, Value: f(member.Value), Optional: member.Optional, Node: member.Node}
+	}
+	return mapped
+}
+
+func mapTypes(types []Type, f func(Type) Type) []Type {
+	mapped := make([]Type, len(types))
+	for i, t := range types {
+		mapped[i] = f(t)
+	}
+	return mapped
+}
+
+func (e *Env) simplifyUnion(types []Type) Type {
+	var elements []Type
+	for _, t := range flattenUnion(mapTypes(types, e.Simplify)) {
+		switch t.(type) {
+		case *MixedType:
+			return t
+		case *NeverType:
+			continue
+		}
+		elements = append(elements, t)
+	}
+	elements = mergeIntRanges(elements)
+	if e.containsType(elements, NewBoolLiteralType(true)) && e.containsType(elements, NewBoolLiteralType(false)) {
+		elements = append(elements, NewBoolType())
+	}
+	elements = absorb(elements, func(a, b Type) bool { return e.IsSubtype(a, b) })
+	return union(elements)
+}
+
+func union(elements []Type) Type {
+	switch len(elements) {
+	case 0:
+		return NewNeverType()
+	case 1:
+		return elements[0]
+	}
+	return &UnionType{Types: elements}
+}
+
+func intersection(elements []Type) Type {
+	switch len(elements) {
+	case 0:
+		return NewMixedType()
+	case 1:
+		return elements[0]
+	}
+	return &IntersectionType{Types: elements}
+}
+
+// absorb drops every element that is redundant next to another one. Of two
+// equivalent elements, the first one is kept.
+func absorb(elements []Type, redundant func(a, b Type) bool) []Type {
+	var kept []Type
+	for i, element := range elements {
+		absorbed := false
+		for j, other := range elements {
+			if i == j || !redundant(element, other) {
+				continue
+			}
+			if j < i || !redundant(other, element) {
+				absorbed = true
+				break
+			}
+		}
+		if !absorbed {
+			kept = append(kept, element)
+		}
+	}
+	return kept
+}
+
+func (e *Env) containsType(elements []Type, t Type) bool {
+	for _, element := range elements {
+		if e.IsSubtype(element, t) && e.IsSubtype(t, element) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeIntRanges(elements []Type) []Type {
+	var ranges []*IntType
+	var others []Type
+	for _, element := range elements {
+		if r, ok := element.(*IntType); ok {
+			ranges = append(ranges, r)
+			continue
+		}
+		others = append(others, element)
+	}
+	if len(ranges) < 2 {
+		return elements
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		a, b := ranges[i].Min, ranges[j].Min
+		if a == nil {
+			return b != nil
+		}
+		return b != nil && *a < *b
+	})
+	merged := []*IntType{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := merged[len(merged)-1]
+		if last.Max != nil && r.Min != nil && *r.Min > *last.Max+1 {
+			merged = append(merged, r)
+			continue
+		}
+		if last.Max != nil && (r.Max == nil || *r.Max > *last.Max) {
+			merged[len(merged)-1] = &IntType{Min: last.Min, Max: r.Max}
+		}
+	}
+	result := make([]Type, 0, len(merged)+len(others))
+	for _, r := range merged {
+		result = append(result, r)
+	}
+	return append(result, others...)
+}
+
+func (e *Env) simplifyIntersection(types []Type) Type {
+	elements := flattenIntersection(mapTypes(types, e.Simplify))
+	for i, element := range elements {
+		u, ok := element.(*UnionType)
+		if !ok {
+			continue
+		}
+		rest := append(append([]Type{}, elements[:i]...), elements[i+1:]...)
+		distributed := make([]Type, len(u.Types))
+		for j, t := range u.Types {
+			distributed[j] = e.simplifyIntersection(append([]Type{t}, rest...))
+		}
+		return e.simplifyUnion(distributed)
+	}
+	var kept []Type
+	var shape *ShapeType
+	var objectShape *ObjectShapeType
+	var ints Type
+	for _, element := range elements {
+		switch t := element.(type) {
+		case *NeverType:
+			return t
+		case *MixedType:
+			continue
+		case *ShapeType:
+			if shape == nil {
+				shape = t
+				continue
+			}
+			merged, ok := e.mergeShapes(shape, t)
+			if !ok {
+				return NewNeverType()
+			}
+			shape = merged
+			continue
+		case *ObjectShapeType:
+			if objectShape == nil {
+				objectShape = t
+				continue
+			}
+			merged, ok := e.mergeShapes(&ShapeType{Members: objectShape.Properties}, &ShapeType{Members: t.Properties})
+			if !ok {
+				return NewNeverType()
+			}
+			objectShape = &ObjectShapeType{Properties: merged.Members}
+			continue
+		case *IntType, *IntLiteralType:
+			if ints == nil {
+				ints = t
+				continue
+			}
+			met, ok := meetInts(ints, t)
+			if !ok {
+				return NewNeverType()
+			}
+			ints = met
+			continue
+		}
+		kept = append(kept, element)
+	}
+	if shape != nil {
+		kept = append(kept, shape)
+	}
+	if objectShape != nil {
+		kept = append(kept, objectShape)
+	}
+	if ints != nil {
+		kept = append(kept, ints)
+	}
+	for i, a := range kept {
+		for _, b := range kept[i+1:] {
+			if disjoint(a, b) {
+				return NewNeverType()
+			}
+		}
+	}
+	kept = absorb(kept, func(a, b Type) bool { return e.IsSubtype(b, a) })
+	return intersection(kept)
+}
+
+func (e *Env) mergeShapes(a, b *ShapeType) (*ShapeType, bool) {
+	members := make([]*ShapeMember, 0, len(a.Members)+len(b.Members))
+	for _, member := range a.Members {
+		other := findMember(b.Members, member.Key)
+		if other == nil {
+			members = append(members, member)
+			continue
+		}
+		value := e.simplifyIntersection([]Type{member.Value, other.Value})
+		optional := member.Optional && other.Optional
+		if _, never := value.(*NeverType); never && !optional {
+			return nil, false
+		}
+		members = append(members, &ShapeMember{Key: member.Key, Value: value, Optional: optional, Node: member.Node})
+	}
+	for _, member := range b.Members {
+		if findMember(a.Members, member.Key) == nil {
+			members = append(members, member)
+		}
+	}
+	merged := &ShapeType{Members: members, Sealed: a.Sealed && b.Sealed, List: a.List || b.List}
+	switch {
+	case merged.Sealed:
+	case a.Sealed:
+		merged.ExtraKey, merged.ExtraValue = b.ExtraKey, b.ExtraValue
+	case b.Sealed:
+		merged.ExtraKey, merged.ExtraValue = a.ExtraKey, a.ExtraValue
+	default:
+		aKey, aValue := a.extra()
+		bKey, bValue := b.extra()
+		merged.ExtraKey, merged.ExtraValue = extras(
+			e.simplifyIntersection([]Type{aKey, bKey}),
+			e.simplifyIntersection([]Type{aValue, bValue}),
+		)
+	}
+	return merged, true
+}
+
+func intBounds(t Type) (*int, *int) {
+	switch t := t.(type) {
+	case *IntType:
+		return t.Min, t.Max
+	case *IntLiteralType:
+		return &t.Value, &t.Value
+	}
+	return nil, nil
+}
+
+func meetInts(a, b Type) (Type, bool) {
+	aMin, aMax := intBounds(a)
+	bMin, bMax := intBounds(b)
+	min, max := aMin, aMax
+	if min == nil || bMin != nil && *bMin > *min {
+		min = bMin
+	}
+	if max == nil || bMax != nil && *bMax < *max {
+		max = bMax
+	}
+	if min != nil && max != nil {
+		if *min > *max {
+			return nil, false
+		}
+		if *min == *max {
+			return NewIntLiteralType(*min), true
+		}
+	}
+	return &IntType{Min: min, Max: max}, true
+}
+
+func typeKind(t Type) string {
+	switch t := t.(type) {
+	case *NullType:
+		return "null"
+	case *VoidType:
+		return "void"
+	case *BoolType, *BoolLiteralType:
+		return "bool"
+	case *IntType, *IntLiteralType:
+		return "int"
+	case *FloatType:
+		return "float"
+	case *StringType, *StringLiteralType, *ClassStringType:
+		return "string"
+	case *ArrayType, *ShapeType:
+		return "array"
+	case *ObjectType, *ObjectShapeType, *EnumCaseType:
+		return "object"
+	case *ResourceType:
+		return "resource"
+	case *CallableType:
+		if t.Closure {
+			return "object"
+		}
+	}
+	return ""
+}
+
+// disjoint reports whether no value can be of both types a and b.
+func disjoint(a, b Type) bool {
+	aKind, bKind := typeKind(a), typeKind(b)
+	if aKind == "" || bKind == "" {
+		return false
+	}
+	if aKind != bKind {
+		return true
+	}
+	switch aKind {
+	case "bool":
+		aLiteral, aOk := a.(*BoolLiteralType)
+		bLiteral, bOk := b.(*BoolLiteralType)
+		return aOk && bOk && aLiteral.Value != bLiteral.Value
+	case "string":
+		if literal, ok := a.(*StringLiteralType); ok {
+			return disjointFromStringLiteral(literal, b)
+		}
+		if literal, ok := b.(*StringLiteralType); ok {
+			return disjointFromStringLiteral(literal, a)
+		}
+	case "int":
+		_, ok := meetInts(a, b)
+		return !ok
+	case "object":
+		aCase, aOk := a.(*EnumCaseType)
+		bCase, bOk := b.(*EnumCaseType)
+		return aOk && bOk && (!sameClass(aCase.Enum, bCase.Enum) || aCase.Case != bCase.Case)
+	}
+	return false
+}
+
+func disjointFromStringLiteral(literal *StringLiteralType, t Type) bool {
+	switch t.(type) {
+	case *StringLiteralType, *StringType:
+		return !IsSubtype(literal, t)
+	}
+	return false
+}
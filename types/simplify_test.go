@@ -0,0 +1,107 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		t    types.Type
+		want string
+	}{
+		{types.NewUnionType(types.NewIntType(), types.NewIntRangeType(intPtr(1), nil)), "int"},
+		{types.NewUnionType(types.NewIntRangeType(intPtr(1), nil), types.NewIntType()), "int"},
+		{types.NewUnionType(types.NewStringLiteralType("a"), types.NewStringType()), "string"},
+		{types.NewUnionType(types.NewStringType(), types.NewStringType()), "string"},
+		{types.NewUnionType(types.NewStringLiteralType("a"), types.NewStringLiteralType("b")), "\"a\" | \"b\""},
+		{types.NewUnionType(types.NewIntType(), types.NewNeverType()), "int"},
+		{types.NewUnionType(types.NewIntType(), types.NewMixedType()), "mixed"},
+		{types.NewUnionType(types.NewBoolLiteralType(true), types.NewBoolLiteralType(false)), "bool"},
+		{
+			types.NewUnionType(types.NewIntRangeType(intPtr(0), intPtr(5)), types.NewIntRangeType(intPtr(6), intPtr(10))),
+			"int<0, 10>",
+		},
+		{
+			types.NewUnionType(types.NewIntRangeType(intPtr(0), intPtr(5)), types.NewIntRangeType(intPtr(7), intPtr(10))),
+			"int<0, 5> | int<7, 10>",
+		},
+		{
+			types.NewUnionType(types.NewIntRangeType(nil, intPtr(-1)), types.NewIntRangeType(intPtr(0), nil)),
+			"int",
+		},
+		{
+			types.NewIntersectionType(
+				types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewIntType())}),
+				types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("bar", types.NewStringType())}),
+			),
+			"array{foo: int, bar: string}",
+		},
+		{
+			types.NewIntersectionType(
+				types.NewShapeType([]*types.ShapeMember{types.NewOptionalShapeMember("foo", types.NewIntType())}),
+				types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewIntRangeType(intPtr(1), nil))}),
+			),
+			"array{foo: positive-int}",
+		},
+		{
+			types.NewIntersectionType(
+				types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewIntType())}),
+				types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewStringType())}),
+			),
+			"never",
+		},
+		{types.NewIntersectionType(types.NewIntType(), types.NewStringType()), "never"},
+		{types.NewIntersectionType(types.NewStringLiteralType("a"), types.NewStringLiteralType("b")), "never"},
+		{types.NewIntersectionType(types.NewStringLiteralType(""), types.NewNonEmptyStringType()), "never"},
+		{types.NewIntersectionType(types.NewBoolLiteralType(true), types.NewBoolType()), "true"},
+		{types.NewIntersectionType(types.NewIntType(), types.NewIntRangeType(intPtr(1), nil)), "positive-int"},
+		{
+			types.NewIntersectionType(types.NewIntRangeType(intPtr(0), intPtr(10)), types.NewIntRangeType(intPtr(5), nil)),
+			"int<5, 10>",
+		},
+		{types.NewIntersectionType(types.NewIntRangeType(nil, intPtr(0)), types.NewIntRangeType(intPtr(1), nil)), "never"},
+		{types.NewIntersectionType(types.NewIntRangeType(intPtr(3), nil), types.NewIntRangeType(nil, intPtr(3))), "3"},
+		{types.NewIntersectionType(types.NewObjectType("Foo"), types.NewObjectType("Bar")), "Foo & Bar"},
+		{types.NewIntersectionType(types.NewObjectType("Foo"), types.NewMixedType()), "Foo"},
+		{
+			types.NewIntersectionType(
+				types.NewUnionType(types.NewIntType(), types.NewStringType()),
+				types.NewUnionType(types.NewStringType(), types.NewNullType()),
+			),
+			"string",
+		},
+		{
+			types.NewListType(types.NewUnionType(types.NewIntType(), types.NewIntLiteralType(3))),
+			"list<int>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.t.String(), func(t *testing.T) {
+			if got := types.Simplify(test.t); got.String() != test.want {
+				t.Errorf("Simplify(%s) = %s, want %s", test.t, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSimplifyNode(t *testing.T) {
+	node := parser.NewUnionNode(
+		parser.NewSimpleNode("int"),
+		parser.NewSimpleNode("positive-int"),
+		parser.NewStringLiteralNode("a"),
+		parser.NewSimpleNode("string"),
+	)
+	got, err := types.SimplifyNode(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "int | string" {
+		t.Errorf("SimplifyNode(%s) = %s, want int | string", node, got)
+	}
+	if _, ok := got.(*parser.UnionNode); !ok {
+		t.Errorf("expected a UnionNode, got %T", got)
+	}
+}
@@ -0,0 +1,595 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+var numericPattern = regexp.MustCompile(`^\s*[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?\s*$`)
+
+// IsSubtype is (&Env{}).IsSubtype.
+func IsSubtype(sub, super Type) bool {
+	return (&Env{}).IsSubtype(sub, super)
+}
+
+// Explain is (&Env{}).Explain.
+func Explain(sub, super Type) *Reason {
+	return (&Env{}).Explain(sub, super)
+}
+
+func (e *Env) IsSubtype(sub, super Type) bool {
+	return e.Explain(sub, super) == nil
+}
+
+// Explain returns nil if sub is a subtype of super. Otherwise, it returns the
+// reason why it is not, with nested reasons for the parts that do not match.
+func (e *Env) Explain(sub, super Type) *Reason {
+	switch super.(type) {
+	case *MixedType:
+		if _, ok := sub.(*VoidType); ok {
+			return notSubtype(sub, super)
+		}
+		return nil
+	}
+	switch s := sub.(type) {
+	case *NeverType:
+		return nil
+	case *TemplateType:
+		if mentionsTemplateType(super, s) {
+			return nil
+		}
+		bound := s.Bound
+		if bound == nil {
+			bound = NewMixedType()
+		}
+		if reason := e.Explain(bound, super); reason != nil {
+			return notSubtype(sub, super, reason)
+		}
+		return nil
+	case *UnionType:
+		for _, element := range s.Types {
+			if reason := e.Explain(element, super); reason != nil {
+				return notSubtype(sub, super, reason)
+			}
+		}
+		return nil
+	}
+	if intersection, ok := super.(*IntersectionType); ok {
+		for _, element := range intersection.Types {
+			if reason := e.Explain(sub, element); reason != nil {
+				return notSubtype(sub, super, reason)
+			}
+		}
+		return nil
+	}
+	if intersection, ok := sub.(*IntersectionType); ok {
+		for _, element := range intersection.Types {
+			if e.IsSubtype(element, super) {
+				return nil
+			}
+		}
+		return notSubtype(sub, super)
+	}
+	if union, ok := super.(*UnionType); ok {
+		if _, ok := sub.(*BoolType); ok {
+			return e.Explain(NewUnionType(NewBoolLiteralType(true), NewBoolLiteralType(false)), super)
+		}
+		var reasons []*Reason
+		for _, element := range union.Types {
+			reason := e.Explain(sub, element)
+			if reason == nil {
+				return nil
+			}
+			if len(reason.Reasons) > 0 {
+				reasons = append(reasons, reason)
+			}
+		}
+		if cases, ok := e.ExpandEnum(sub); ok {
+			if reason := e.Explain(cases, super); reason != nil {
+				return notSubtype(sub, super, reason.Reasons...)
+			}
+			return nil
+		}
+		return notSubtype(sub, super, reasons...)
+	}
+	return e.explainAtomic(sub, super)
+}
+
+func notSubtype(sub, super Type, reasons ...*Reason) *Reason {
+	return newReason(fmt.Sprintf("%s is not a subtype of %s", sub, super), reasons...)
+}
+
+func (e *Env) explainAtomic(sub, super Type) *Reason {
+	ok := false
+	switch p := super.(type) {
+	case *NeverType:
+		ok = false
+	case *VoidType:
+		_, ok = sub.(*VoidType)
+	case *NullType:
+		_, ok = sub.(*NullType)
+	case *ResourceType:
+		_, ok = sub.(*ResourceType)
+	case *FloatType:
+		_, ok = sub.(*FloatType)
+	case *BoolType:
+		switch sub.(type) {
+		case *BoolType, *BoolLiteralType:
+			ok = true
+		}
+	case *BoolLiteralType:
+		s, isLiteral := sub.(*BoolLiteralType)
+		ok = isLiteral && s.Value == p.Value
+	case *IntType:
+		ok = isIntSubtype(sub, p)
+	case *IntLiteralType:
+		ok = isIntSubtype(sub, &IntType{Min: &p.Value, Max: &p.Value})
+	case *StringType:
+		ok = isStringSubtype(sub, p)
+	case *StringLiteralType:
+		s, isLiteral := sub.(*StringLiteralType)
+		ok = isLiteral && s.Value == p.Value
+	case *EnumCaseType:
+		if cases, isEnum := e.ExpandEnum(sub); isEnum {
+			return e.Explain(cases, super)
+		}
+		s, isCase := sub.(*EnumCaseType)
+		ok = isCase && sameClass(s.Enum, p.Enum) && s.Case == p.Case
+	case *ClassStringType:
+		s, isClassString := sub.(*ClassStringType)
+		ok = isClassString && (p.Class == nil || s.Class != nil && e.IsSubtype(s.Class, p.Class))
+	case *ArrayType:
+		return e.explainArray(sub, p)
+	case *IterableType:
+		return e.explainIterable(sub, p)
+	case *ShapeType:
+		return e.explainShape(sub, p)
+	case *ObjectShapeType:
+		return e.explainObjectShape(sub, p)
+	case *ObjectType:
+		return e.explainObject(sub, p)
+	case *CallableType:
+		return e.explainCallable(sub, p)
+	}
+	if ok {
+		return nil
+	}
+	return notSubtype(sub, super)
+}
+
+func isIntSubtype(sub Type, super *IntType) bool {
+	var min, max *int
+	switch s := sub.(type) {
+	case *IntType:
+		min, max = s.Min, s.Max
+	case *IntLiteralType:
+		min, max = &s.Value, &s.Value
+	default:
+		return false
+	}
+	if super.Min != nil && (min == nil || *min < *super.Min) {
+		return false
+	}
+	if super.Max != nil && (max == nil || *max > *super.Max) {
+		return false
+	}
+	return true
+}
+
+func isStringSubtype(sub Type, super *StringType) bool {
+	switch s := sub.(type) {
+	case *StringType:
+		return (!super.Numeric || s.Numeric) && (!super.NonEmpty || s.NonEmpty || s.Numeric)
+	case *StringLiteralType:
+		return (!super.Numeric || numericPattern.MatchString(s.Value)) && (!super.NonEmpty || s.Value != "")
+	case *ClassStringType:
+		return !super.Numeric
+	}
+	return false
+}
+
+func (e *Env) explainArray(sub Type, super *ArrayType) *Reason {
+	switch s := sub.(type) {
+	case *ArrayType:
+		if super.List && !s.List {
+			return notSubtype(sub, super, newReason(fmt.Sprintf("%s is not a list", sub)))
+		}
+		if super.NonEmpty && !s.NonEmpty {
+			return notSubtype(sub, super, newReason(fmt.Sprintf("%s may be empty", sub)))
+		}
+		if reason := e.Explain(s.Key, super.Key); reason != nil {
+			return notSubtype(sub, super, newReason("key types are incompatible", reason))
+		}
+		if reason := e.Explain(s.Value, super.Value); reason != nil {
+			return notSubtype(sub, super, newReason("value types are incompatible", reason))
+		}
+		return nil
+	case *ShapeType:
+		if super.List && !isListShape(s) {
+			return notSubtype(sub, super, newReason(fmt.Sprintf("%s is not a list", sub)))
+		}
+		if super.NonEmpty && !hasRequiredMember(s.Members) {
+			return notSubtype(sub, super, newReason(fmt.Sprintf("%s may be empty", sub)))
+		}
+		for _, member := range s.Members {
+			if reason := e.Explain(shapeKeyType(member.Key), super.Key); reason != nil {
+				return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("key %s is incompatible", formatKey(member.Key)), reason))
+			}
+			if reason := e.Explain(member.Value, super.Value); reason != nil {
+				return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("member %s has an incompatible type", formatKey(member.Key)), reason))
+			}
+		}
+		if !s.Sealed {
+			key, value := s.extra()
+			if reason := e.Explain(key, super.Key); reason != nil {
+				return notSubtype(sub, super, newReason("extra key types are incompatible", reason))
+			}
+			if reason := e.Explain(value, super.Value); reason != nil {
+				return notSubtype(sub, super, newReason("extra value types are incompatible", reason))
+			}
+		}
+		return nil
+	}
+	return notSubtype(sub, super)
+}
+
+func (e *Env) explainIterable(sub Type, super *IterableType) *Reason {
+	var key, value Type
+	switch s := sub.(type) {
+	case *IterableType:
+		key, value = s.Key, s.Value
+	case *ArrayType:
+		key, value = s.Key, s.Value
+	case *ShapeType:
+		if reason := e.explainArray(sub, &ArrayType{Key: super.Key, Value: super.Value}); reason != nil {
+			return notSubtype(sub, super, reason.Reasons...)
+		}
+		return nil
+	default:
+		return notSubtype(sub, super)
+	}
+	if reason := e.Explain(key, super.Key); reason != nil {
+		return notSubtype(sub, super, newReason("key types are incompatible", reason))
+	}
+	if reason := e.Explain(value, super.Value); reason != nil {
+		return notSubtype(sub, super, newReason("value types are incompatible", reason))
+	}
+	return nil
+}
+
+func shapeKeyType(key string) Type {
+	if i, err := strconv.Atoi(key); err == nil && strconv.Itoa(i) == key {
+		return NewIntLiteralType(i)
+	}
+	return NewStringLiteralType(key)
+}
+
+func isListShape(shape *ShapeType) bool {
+	if shape.List {
+		return true
+	}
+	if !shape.Sealed {
+		return false
+	}
+	for i, member := range shape.Members {
+		if member.Key != strconv.Itoa(i) {
+			return false
+		}
+		if member.Optional && i+1 < len(shape.Members) && !shape.Members[i+1].Optional {
+			return false
+		}
+	}
+	return true
+}
+
+func hasRequiredMember(members []*ShapeMember) bool {
+	for _, member := range members {
+		if !member.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+func findMember(members []*ShapeMember, key string) *ShapeMember {
+	for _, member := range members {
+		if member.Key == key {
+			return member
+		}
+	}
+	return nil
+}
+
+func (e *Env) explainShape(sub Type, super *ShapeType) *Reason {
+	if a, ok := sub.(*ArrayType); ok && !super.Sealed {
+		return e.explainArrayAsShape(a, super)
+	}
+	s, ok := sub.(*ShapeType)
+	if !ok {
+		return notSubtype(sub, super)
+	}
+	if super.List && !isListShape(s) {
+		return notSubtype(sub, super, newReason(fmt.Sprintf("%s is not a list", sub)))
+	}
+	if reason := e.explainMembers(s.Members, super.Members, "member"); reason != nil {
+		return notSubtype(sub, super, reason)
+	}
+	key, value := super.extra()
+	for _, member := range s.Members {
+		if findMember(super.Members, member.Key) != nil {
+			continue
+		}
+		if super.Sealed {
+			return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("member %s is not allowed in target", formatKey(member.Key))))
+		}
+		if reason := e.Explain(shapeKeyType(member.Key), key); reason != nil {
+			return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("key %s is incompatible", formatKey(member.Key)), reason))
+		}
+		if reason := e.Explain(member.Value, value); reason != nil {
+			return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("member %s has an incompatible type", formatKey(member.Key)), reason))
+		}
+	}
+	if s.Sealed {
+		return nil
+	}
+	if super.Sealed {
+		return notSubtype(sub, super, newReason(fmt.Sprintf("%s is unsealed but %s is sealed", sub, super)))
+	}
+	extraKey, extraValue := s.extra()
+	for _, member := range super.Members {
+		if findMember(s.Members, member.Key) != nil || !e.IsSubtype(shapeKeyType(member.Key), extraKey) {
+			continue
+		}
+		if reason := e.Explain(extraValue, member.Value); reason != nil {
+			return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("member %s has an incompatible type", formatKey(member.Key)), reason))
+		}
+	}
+	if reason := e.Explain(extraKey, key); reason != nil {
+		return notSubtype(sub, super, newReason("extra key types are incompatible", reason))
+	}
+	if reason := e.Explain(extraValue, value); reason != nil {
+		return notSubtype(sub, super, newReason("extra value types are incompatible", reason))
+	}
+	return nil
+}
+
+// explainArrayAsShape checks a generic array against an unsealed shape, whose
+// members must all be optional since the array may lack any key.
+func (e *Env) explainArrayAsShape(sub *ArrayType, super *ShapeType) *Reason {
+	if super.List && !sub.List {
+		return notSubtype(sub, super, newReason(fmt.Sprintf("%s is not a list", sub)))
+	}
+	for _, member := range super.Members {
+		if !member.Optional {
+			return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("member %s is required in target but missing in source", formatKey(member.Key))))
+		}
+		if !e.IsSubtype(shapeKeyType(member.Key), sub.Key) {
+			continue
+		}
+		if reason := e.Explain(sub.Value, member.Value); reason != nil {
+			return notSubtype(sub, super, newMemberReason(member, fmt.Sprintf("member %s has an incompatible type", formatKey(member.Key)), reason))
+		}
+	}
+	key, value := super.extra()
+	if reason := e.Explain(sub.Key, key); reason != nil {
+		return notSubtype(sub, super, newReason("key types are incompatible", reason))
+	}
+	if reason := e.Explain(sub.Value, value); reason != nil {
+		return notSubtype(sub, super, newReason("value types are incompatible", reason))
+	}
+	return nil
+}
+
+func (e *Env) explainObjectShape(sub Type, super *ObjectShapeType) *Reason {
+	var properties []*ShapeMember
+	switch s := sub.(type) {
+	case *ObjectShapeType:
+		properties = s.Properties
+	case *ObjectType:
+		if s.Class == "" {
+			return notSubtype(sub, super)
+		}
+		declared, err := e.properties(s)
+		if err != nil {
+			return notSubtype(sub, super, newReason(err.Error()))
+		}
+		properties = declared
+	default:
+		return notSubtype(sub, super)
+	}
+	if reason := e.explainMembers(properties, super.Properties, "property"); reason != nil {
+		return notSubtype(sub, super, reason)
+	}
+	return nil
+}
+
+func (e *Env) explainMembers(sub, super []*ShapeMember, kind string) *Reason {
+	for _, member := range super {
+		actual := findMember(sub, member.Key)
+		if actual == nil {
+			if member.Optional {
+				continue
+			}
+			return newMemberReason(member, fmt.Sprintf("%s %s is required in target but missing in source", kind, formatKey(member.Key)))
+		}
+		if actual.Optional && !member.Optional {
+			return newMemberReason(actual, fmt.Sprintf("%s %s is required in target but optional in source", kind, formatKey(member.Key)))
+		}
+		if reason := e.Explain(actual.Value, member.Value); reason != nil {
+			return newMemberReason(actual, fmt.Sprintf("%s %s has an incompatible type", kind, formatKey(member.Key)), reason)
+		}
+	}
+	return nil
+}
+
+func (e *Env) explainObject(sub Type, super *ObjectType) *Reason {
+	if c, ok := sub.(*CallableType); ok && c.Closure && (super.Class == "" || sameClass(super.Class, "Closure") && len(super.TypeArguments) == 0) {
+		return nil
+	}
+	if super.Class == "" {
+		switch sub.(type) {
+		case *ObjectType, *ObjectShapeType, *EnumCaseType:
+			return nil
+		}
+		return notSubtype(sub, super)
+	}
+	object := sub
+	if c, ok := sub.(*EnumCaseType); ok {
+		object = NewObjectType(c.Enum)
+	}
+	s, ok := object.(*ObjectType)
+	if !ok || s.Class == "" {
+		return notSubtype(sub, super)
+	}
+	s, err := e.ancestor(s, super.Class)
+	if err != nil {
+		return notSubtype(sub, super, newReason(err.Error()))
+	}
+	if s == nil {
+		return notSubtype(sub, super)
+	}
+	if len(super.TypeArguments) == 0 {
+		return nil
+	}
+	if len(s.TypeArguments) != len(super.TypeArguments) {
+		return notSubtype(sub, super, newReason(fmt.Sprintf("expected %d type arguments, got %d", len(super.TypeArguments), len(s.TypeArguments))))
+	}
+	for i, argument := range super.TypeArguments {
+		if reason := e.explainTypeArgument(s.TypeArguments[i], s.variance(i), argument, e.variance(super, i)); reason != nil {
+			return notSubtype(sub, super, newReason(fmt.Sprintf("type argument %d is incompatible", i+1), reason))
+		}
+	}
+	return nil
+}
+
+// explainTypeArgument compares a type argument of the subtype with the
+// corresponding one of the supertype. The variance of the subtype's argument
+// is its call-site variance only, as an argument without one is exact.
+func (e *Env) explainTypeArgument(sub Type, subVariance parser.Variance, super Type, superVariance parser.Variance) *Reason {
+	if superVariance == parser.Bivariant {
+		return nil
+	}
+	if subVariance != parser.Invariant && subVariance != superVariance {
+		argument := fmt.Sprintf("%s %s", subVariance, sub)
+		if subVariance == parser.Bivariant {
+			argument = "*"
+		}
+		return newReason(fmt.Sprintf("%s is not %s", argument, superVariance))
+	}
+	switch superVariance {
+	case parser.Covariant:
+		return e.Explain(sub, super)
+	case parser.Contravariant:
+		return e.Explain(super, sub)
+	}
+	if e.IsSubtype(sub, super) && e.IsSubtype(super, sub) {
+		return nil
+	}
+	return newReason(fmt.Sprintf("%s is not equal to %s", sub, super))
+}
+
+func sameClass(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(a, "\\"), strings.TrimPrefix(b, "\\"))
+}
+
+func (e *Env) explainCallable(sub Type, super *CallableType) *Reason {
+	if o, ok := sub.(*ObjectType); ok && sameClass(o.Class, "Closure") && len(o.TypeArguments) == 0 {
+		if super.ReturnType == nil && !super.Pure {
+			return nil
+		}
+	}
+	s, ok := sub.(*CallableType)
+	if !ok {
+		return notSubtype(sub, super)
+	}
+	if super.Closure && !s.Closure {
+		return notSubtype(sub, super, newReason(fmt.Sprintf("%s is not a Closure", s.Keyword())))
+	}
+	if super.Pure && !s.Pure {
+		return notSubtype(sub, super, newReason(fmt.Sprintf("%s is not pure", s.Keyword())))
+	}
+	if super.ReturnType == nil {
+		return nil
+	}
+	if s.ReturnType == nil {
+		return notSubtype(sub, super, newReason(fmt.Sprintf("the signature of %s is unknown", sub)))
+	}
+	if len(s.Templates) > 0 {
+		instantiated, reason := e.instantiate(s, super)
+		if reason != nil {
+			return notSubtype(sub, super, reason)
+		}
+		s = instantiated
+	}
+	for i := 0; i < len(super.Parameters) || i < len(s.Parameters); i++ {
+		parameter, actual := paramAt(super.Parameters, i), paramAt(s.Parameters, i)
+		if actual == nil {
+			break
+		}
+		if parameter == nil {
+			if !actual.isOptional() {
+				return notSubtype(sub, super, newParamReason(actual, fmt.Sprintf("parameter %d of callable is required in source but missing in target", i+1)))
+			}
+			continue
+		}
+		if parameter.isOptional() && !actual.isOptional() {
+			return notSubtype(sub, super, newParamReason(actual, fmt.Sprintf("parameter %d of callable is optional in target but required in source", i+1)))
+		}
+		if parameter.ByRef != actual.ByRef {
+			return notSubtype(sub, super, newParamReason(actual, fmt.Sprintf("parameter %d of callable is passed by %s in source but by %s in target", i+1, passing(actual), passing(parameter))))
+		}
+		if reason := e.Explain(parameter.Type, actual.Type); reason != nil {
+			return notSubtype(sub, super, newParamReason(actual, fmt.Sprintf("parameter %d of callable has an incompatible type", i+1), reason))
+		}
+	}
+	if _, ok := super.ReturnType.(*VoidType); ok {
+		return nil
+	}
+	if reason := e.Explain(s.ReturnType, super.ReturnType); reason != nil {
+		return notSubtype(sub, super, newReason("return types are incompatible", reason))
+	}
+	return nil
+}
+
+// mentionsTemplateType reports whether super is the template t itself or a
+// union that contains it.
+func mentionsTemplateType(super Type, t *TemplateType) bool {
+	switch super := super.(type) {
+	case *TemplateType:
+		return super.Name == t.Name
+	case *UnionType:
+		for _, element := range super.Types {
+			if mentionsTemplateType(element, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paramAt returns the parameter that receives the i-th argument, which is
+// the trailing variadic parameter for every argument past the declared ones.
+func paramAt(parameters []*CallableParam, i int) *CallableParam {
+	if i < len(parameters) {
+		return parameters[i]
+	}
+	if n := len(parameters); n > 0 && parameters[n-1].Variadic {
+		return parameters[n-1]
+	}
+	return nil
+}
+
+func (p *CallableParam) isOptional() bool {
+	return p.Optional || p.Variadic
+}
+
+func passing(p *CallableParam) string {
+	if p.ByRef {
+		return "reference"
+	}
+	return "value"
+}
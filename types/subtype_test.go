@@ -0,0 +1,374 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestIsSubtype(t *testing.T) {
+	shape := func(members ...*types.ShapeMember) types.Type {
+		return types.NewShapeType(members)
+	}
+	member := types.NewShapeMember
+	optional := types.NewOptionalShapeMember
+	callable := func(returnType types.Type, parameters ...*types.CallableParam) types.Type {
+		return types.NewCallableType(returnType, parameters)
+	}
+	param := types.NewCallableParam
+	optionalParam := types.NewOptionalCallableParam
+	tests := []struct {
+		sub   types.Type
+		super types.Type
+		want  bool
+	}{
+		{types.NewIntType(), types.NewMixedType(), true},
+		{types.NewNeverType(), types.NewStringType(), true},
+		{types.NewMixedType(), types.NewStringType(), false},
+		{types.NewVoidType(), types.NewMixedType(), false},
+		{types.NewIntLiteralType(5), types.NewIntRangeType(intPtr(1), nil), true},
+		{types.NewIntLiteralType(0), types.NewIntRangeType(intPtr(1), nil), false},
+		{types.NewIntRangeType(intPtr(1), intPtr(5)), types.NewIntRangeType(intPtr(0), nil), true},
+		{types.NewIntType(), types.NewIntRangeType(intPtr(0), nil), false},
+		{types.NewIntRangeType(intPtr(3), intPtr(3)), types.NewIntLiteralType(3), true},
+		{types.NewStringLiteralType("foo"), types.NewNonEmptyStringType(), true},
+		{types.NewStringLiteralType(""), types.NewNonEmptyStringType(), false},
+		{types.NewStringLiteralType("1.5e3"), types.NewNumericStringType(), true},
+		{types.NewStringLiteralType("foo"), types.NewNumericStringType(), false},
+		{types.NewNumericStringType(), types.NewNonEmptyStringType(), true},
+		{types.NewStringType(), types.NewNonEmptyStringType(), false},
+		{types.NewClassStringType(types.NewObjectType("Foo")), types.NewClassStringType(nil), true},
+		{types.NewClassStringType(nil), types.NewStringType(), true},
+		{types.NewBoolType(), types.NewUnionType(types.NewBoolLiteralType(true), types.NewBoolLiteralType(false)), true},
+		{types.NewBoolLiteralType(true), types.NewBoolType(), true},
+		{types.NewBoolType(), types.NewBoolLiteralType(true), false},
+		{types.NewIntType(), types.NewUnionType(types.NewStringType(), types.NewIntType()), true},
+		{types.NewUnionType(types.NewIntType(), types.NewNullType()), types.NewIntType(), false},
+		{types.NewUnionType(types.NewIntLiteralType(1), types.NewStringLiteralType("a")), types.NewArrayKeyType(), true},
+		{
+			types.NewIntersectionType(types.NewObjectType("Foo"), types.NewObjectType("Bar")),
+			types.NewObjectType("Bar"),
+			true,
+		},
+		{
+			types.NewObjectType("Foo"),
+			types.NewIntersectionType(types.NewObjectType("Foo"), types.NewObjectType("Bar")),
+			false,
+		},
+		{types.NewObjectType("Foo"), types.NewObjectType(""), true},
+		{types.NewObjectType("\\App\\Foo"), types.NewObjectType("app\\foo"), true},
+		{types.NewObjectType("Foo", types.NewIntType()), types.NewObjectType("Foo"), true},
+		{types.NewObjectType("Foo", types.NewIntLiteralType(1)), types.NewObjectType("Foo", types.NewIntType()), false},
+		{
+			types.NewListType(types.NewIntRangeType(intPtr(1), nil)),
+			types.NewArrayType(types.NewIntType(), types.NewIntType()),
+			true,
+		},
+		{
+			types.NewArrayType(types.NewIntType(), types.NewIntType()),
+			types.NewListType(types.NewIntType()),
+			false,
+		},
+		{
+			types.NewArrayType(types.NewIntType(), types.NewIntType()),
+			types.NewNonEmptyArrayType(types.NewIntType(), types.NewIntType()),
+			false,
+		},
+		{
+			types.NewNonEmptyListType(types.NewStringType()),
+			types.NewIterableType(types.NewMixedType(), types.NewStringType()),
+			true,
+		},
+		{
+			shape(member("0", types.NewIntType()), member("1", types.NewStringType())),
+			types.NewListType(types.NewArrayKeyType()),
+			true,
+		},
+		{
+			shape(member("foo", types.NewIntType())),
+			types.NewNonEmptyArrayType(types.NewStringType(), types.NewIntType()),
+			true,
+		},
+		{
+			shape(optional("foo", types.NewIntType())),
+			types.NewNonEmptyArrayType(types.NewStringType(), types.NewIntType()),
+			false,
+		},
+		{
+			shape(member("foo", types.NewStringType())),
+			shape(optional("foo", types.NewStringType()), optional("bar", types.NewIntType())),
+			true,
+		},
+		{
+			shape(optional("foo", types.NewStringType())),
+			shape(member("foo", types.NewStringType())),
+			false,
+		},
+		{
+			shape(member("foo", types.NewStringType()), member("bar", types.NewIntType())),
+			shape(member("foo", types.NewStringType())),
+			false,
+		},
+		{
+			shape(member("foo", types.NewStringLiteralType("x"))),
+			shape(member("foo", types.NewStringType())),
+			true,
+		},
+		{
+			types.NewObjectShapeType([]*types.ShapeMember{member("foo", types.NewIntType()), member("bar", types.NewIntType())}),
+			types.NewObjectShapeType([]*types.ShapeMember{member("foo", types.NewIntType())}),
+			true,
+		},
+		{
+			callable(types.NewVoidType(), param(types.NewIntType())),
+			callable(types.NewVoidType(), optionalParam(types.NewIntType())),
+			false,
+		},
+		{
+			callable(types.NewVoidType(), optionalParam(types.NewIntType())),
+			callable(types.NewVoidType(), param(types.NewIntType())),
+			true,
+		},
+		{
+			callable(types.NewVoidType(), param(types.NewIntType())),
+			callable(types.NewVoidType(), param(types.NewIntRangeType(intPtr(1), nil))),
+			true,
+		},
+		{
+			callable(types.NewVoidType(), param(types.NewIntRangeType(intPtr(1), nil))),
+			callable(types.NewVoidType(), param(types.NewIntType())),
+			false,
+		},
+		{
+			callable(types.NewVoidType()),
+			callable(types.NewVoidType(), param(types.NewIntType())),
+			true,
+		},
+		{
+			callable(types.NewVoidType(), param(types.NewIntType()), param(types.NewIntType())),
+			callable(types.NewVoidType(), param(types.NewIntType())),
+			false,
+		},
+		{
+			callable(types.NewIntLiteralType(1)),
+			callable(types.NewIntType()),
+			true,
+		},
+		{
+			callable(types.NewIntType()),
+			callable(types.NewVoidType()),
+			true,
+		},
+		{callable(types.NewIntType()), types.NewAnyCallableType(), true},
+		{types.NewAnyCallableType(), callable(types.NewIntType()), false},
+	}
+	for _, test := range tests {
+		t.Run(test.sub.String()+" <: "+test.super.String(), func(t *testing.T) {
+			if got := types.IsSubtype(test.sub, test.super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", test.sub, test.super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		sub   types.Type
+		super types.Type
+		want  string
+	}{
+		{
+			types.NewIntType(),
+			types.NewIntType(),
+			"",
+		},
+		{
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewIntType())}),
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewStringType())}),
+			"array{foo: int} is not a subtype of array{foo: string}\n" +
+				"  member foo has an incompatible type\n" +
+				"    int is not a subtype of string",
+		},
+		{
+			types.NewListType(types.NewShapeType([]*types.ShapeMember{types.NewOptionalShapeMember("bar", types.NewIntType())})),
+			types.NewListType(types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("bar", types.NewIntType())})),
+			"list<array{bar?: int}> is not a subtype of list<array{bar: int}>\n" +
+				"  value types are incompatible\n" +
+				"    array{bar?: int} is not a subtype of array{bar: int}\n" +
+				"      member bar is required in target but optional in source",
+		},
+		{
+			types.NewCallableType(types.NewVoidType(), []*types.CallableParam{
+				types.NewCallableParam(types.NewIntType()),
+				types.NewCallableParam(types.NewIntType()),
+			}),
+			types.NewCallableType(types.NewVoidType(), []*types.CallableParam{
+				types.NewCallableParam(types.NewIntType()),
+				types.NewOptionalCallableParam(types.NewIntType()),
+			}),
+			"callable(int, int): void is not a subtype of callable(int, int=): void\n" +
+				"  parameter 2 of callable is optional in target but required in source",
+		},
+		{
+			types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewIntType())}),
+			types.NewUnionType(
+				types.NewShapeType([]*types.ShapeMember{types.NewShapeMember("foo", types.NewStringType())}),
+				types.NewNullType(),
+			),
+			"array{foo: int} is not a subtype of array{foo: string} | null\n" +
+				"  array{foo: int} is not a subtype of array{foo: string}\n" +
+				"    member foo has an incompatible type\n" +
+				"      int is not a subtype of string",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.sub.String()+" <: "+test.super.String(), func(t *testing.T) {
+			reason := types.Explain(test.sub, test.super)
+			got := ""
+			if reason != nil {
+				got = reason.String()
+			}
+			if got != test.want {
+				t.Errorf("Explain(%s, %s) =\n%s\nwant\n%s", test.sub, test.super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_IsSubtype_Variance(t *testing.T) {
+	env := &types.Env{Templates: map[string][]*parser.TemplateParam{
+		"ImmutableList": {{Name: "T", Variance: parser.Covariant}},
+		"Consumer":      {{Name: "T", Variance: parser.Contravariant}},
+		"MutableList":   {parser.NewTemplateParam("T", nil)},
+	}}
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"ImmutableList<positive-int>", "ImmutableList<int>", true},
+		{"ImmutableList<int>", "ImmutableList<positive-int>", false},
+		{"Consumer<int>", "Consumer<positive-int>", true},
+		{"Consumer<positive-int>", "Consumer<int>", false},
+		{"MutableList<positive-int>", "MutableList<int>", false},
+		{"MutableList<int>", "MutableList<int>", true},
+		{"MutableList<positive-int>", "MutableList<covariant int>", true},
+		{"MutableList<int>", "MutableList<contravariant positive-int>", true},
+		{"MutableList<positive-int>", "MutableList<contravariant int>", false},
+		{"MutableList<covariant int>", "MutableList<int>", false},
+		{"MutableList<covariant positive-int>", "MutableList<covariant int>", true},
+		{"MutableList<covariant int>", "MutableList<contravariant int>", false},
+		{"MutableList<string>", "MutableList<*>", true},
+		{"MutableList<*>", "MutableList<*>", true},
+		{"MutableList<*>", "MutableList<covariant mixed>", false},
+		{"ImmutableList<covariant positive-int>", "ImmutableList<int>", true},
+		{"Unknown<positive-int>", "Unknown<int>", false},
+	}
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := mustResolve(t, test.sub), mustResolve(t, test.super)
+			if got := env.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnv_Explain_Wildcard(t *testing.T) {
+	env := &types.Env{Templates: map[string][]*parser.TemplateParam{"MutableList": {parser.NewTemplateParam("T", nil)}}}
+	reason := env.Explain(mustResolve(t, "MutableList<*>"), mustResolve(t, "MutableList<int>"))
+	want := "MutableList<*> is not a subtype of MutableList<int>\n" +
+		"  type argument 1 is incompatible\n" +
+		"    * is not invariant"
+	if reason == nil || reason.String() != want {
+		t.Errorf("Explain() =\n%v\nwant\n%s", reason, want)
+	}
+}
+
+func TestIsSubtype_CallableKinds(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  bool
+	}{
+		{"Closure(int): void", "callable(int): void", true},
+		{"callable(int): void", "Closure(int): void", false},
+		{"pure-callable(): int", "callable(): int", true},
+		{"callable(): int", "pure-callable(): int", false},
+		{"pure-Closure(): int", "pure-callable(): int", true},
+		{"pure-Closure(): int", "pure-callable", true},
+		{"Closure(): int", "pure-Closure", false},
+		{"pure-Closure(): int", "pure-Closure", true},
+		{"\\Closure(int): void", "closure(int): void", true},
+		{"Closure(): int", "Closure", true},
+		{"Closure(): int", "object", true},
+		{"callable(): int", "object", false},
+		{"Closure", "callable", true},
+		{"Closure", "callable(): int", false},
+		{"Closure", "pure-callable", false},
+		{"callable(int ...$rest): void", "callable(int, int, int): void", true},
+		{"callable(int ...$rest): void", "callable(): void", true},
+		{"callable(int ...$rest): void", "callable(string): void", false},
+		{"callable(int, int): void", "callable(int ...$rest): void", false},
+		{"callable(int=, int=): void", "callable(int ...$rest): void", true},
+		{"callable(int ...$a): void", "callable(positive-int ...$b): void", true},
+		{"callable(string &$out): void", "callable(string &$s): void", true},
+		{"callable(string &$out): void", "callable(string): void", false},
+		{"callable(string): void", "callable(string &$out): void", false},
+		{"callable(int $x): void", "callable(int $y): void", true},
+	}
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			sub, super := mustResolve(t, test.sub), mustResolve(t, test.super)
+			if got := types.IsSubtype(sub, super); got != test.want {
+				t.Errorf("IsSubtype(%s, %s) = %v, want %v", sub, super, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExplain_QuotedKey(t *testing.T) {
+	reason := types.Explain(mustResolve(t, "array{'a b': int}"), mustResolve(t, "array{'a b': string}"))
+	want := "array{\"a b\": int} is not a subtype of array{\"a b\": string}\n" +
+		"  member \"a b\" has an incompatible type (at 1:7-1:16)\n" +
+		"    int is not a subtype of string"
+	if reason == nil || reason.String() != want {
+		t.Errorf("Explain() =\n%v\nwant\n%s", reason, want)
+	}
+}
+
+func TestExplain_CallableKinds(t *testing.T) {
+	tests := []struct {
+		sub   string
+		super string
+		want  string
+	}{
+		{
+			"callable(): int",
+			"Closure(): int",
+			"callable(): int is not a subtype of Closure(): int\n" +
+				"  callable is not a Closure",
+		},
+		{
+			"Closure(): int",
+			"pure-Closure(): int",
+			"Closure(): int is not a subtype of pure-Closure(): int\n" +
+				"  Closure is not pure",
+		},
+		{
+			"callable(string): void",
+			"callable(string &$out): void",
+			"callable(string): void is not a subtype of callable(string &$out): void\n" +
+				"  parameter 1 of callable is passed by value in source but by reference in target (at 1:10-1:15)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.sub+" <: "+test.super, func(t *testing.T) {
+			reason := types.Explain(mustResolve(t, test.sub), mustResolve(t, test.super))
+			if reason == nil || reason.String() != test.want {
+				t.Errorf("Explain(%s, %s) =\n%v\nwant\n%s", test.sub, test.super, reason, test.want)
+			}
+		})
+	}
+}
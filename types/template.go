@@ -0,0 +1,175 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+// Substitute replaces the template parameters params in node with the given
+// substitutions. Parameters without a substitution fall back to their
+// default, then to their bound, then to mixed. A substitution that does not
+// satisfy the bound of its parameter is refused.
+func Substitute(node parser.Node, params []*parser.TemplateParam, substitutions map[string]parser.Node) (parser.Node, error) {
+	resolved, err := (&Env{}).bindTemplates(params, substitutions)
+	if err != nil {
+		return nil, err
+	}
+	return parser.Substitute(node, resolved), nil
+}
+
+func (e *Env) bindTemplates(params []*parser.TemplateParam, substitutions map[string]parser.Node) (map[string]parser.Node, error) {
+	known := make(map[string]bool, len(params))
+	for _, param := range params {
+		known[param.Name] = true
+	}
+	for name := range substitutions {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown template %s", name)
+		}
+	}
+	resolved := make(map[string]parser.Node, len(params))
+	for _, param := range params {
+		var bound parser.Node
+		if param.Bound != nil {
+			bound = parser.Substitute(param.Bound, resolved)
+		}
+		substitution, ok := substitutions[param.Name]
+		switch {
+		case ok:
+		case param.Default != nil:
+			substitution = parser.Substitute(param.Default, resolved)
+		case bound != nil:
+			substitution = bound
+		default:
+			substitution = parser.NewSimpleNode("mixed")
+		}
+		if bound != nil {
+			if err := e.checkBound(param, substitution, bound); err != nil {
+				return nil, err
+			}
+		}
+		resolved[param.Name] = substitution
+	}
+	return resolved, nil
+}
+
+func (e *Env) checkBound(param *parser.TemplateParam, substitution, bound parser.Node) error {
+	t, err := e.Resolve(substitution)
+	if err != nil {
+		return err
+	}
+	b, err := e.Resolve(bound)
+	if err != nil {
+		return err
+	}
+	if !e.IsSubtype(t, b) {
+		return fmt.Errorf("%s does not satisfy the bound %s of template %s", substitution, bound, param.Name)
+	}
+	return nil
+}
+
+// resolveTemplates resolves the template parameters of a generic callable to
+// template types. A bound may refer to the parameters declared before it.
+func (e *Env) resolveTemplates(params []*parser.TemplateParam) (map[string]Type, error) {
+	templates := make(map[string]Type, len(params))
+	for _, param := range params {
+		var bound Type
+		if param.Bound != nil {
+			resolved, err := e.Resolve(param.Bound)
+			if err != nil {
+				return nil, err
+			}
+			bound = replaceTemplates(resolved, templates)
+		}
+		templates[param.Name] = NewTemplateType(param.Name, bound)
+	}
+	return templates, nil
+}
+
+// replaceTemplates replaces the class names in t that refer to templates with
+// the given types.
+func replaceTemplates(t Type, templates map[string]Type) Type {
+	if object, ok := t.(*ObjectType); ok && len(object.TypeArguments) == 0 {
+		if replacement, ok := templates[object.Class]; ok {
+			return replacement
+		}
+	}
+	return mapChildren(t, func(child Type) Type { return replaceTemplates(child, templates) })
+}
+
+// substituteTemplates replaces the template types in t with the given types.
+// Generic callables nested in t hide the substitutions for their own
+// templates.
+func substituteTemplates(t Type, substitutions map[string]Type) Type {
+	switch t := t.(type) {
+	case *TemplateType:
+		if substitution, ok := substitutions[t.Name]; ok {
+			return substitution
+		}
+		return t
+	case *CallableType:
+		if len(t.Templates) > 0 {
+			shadowed := make(map[string]Type, len(substitutions))
+			for name, substitution := range substitutions {
+				shadowed[name] = substitution
+			}
+			for _, template := range t.Templates {
+				delete(shadowed, template.Name)
+			}
+			substitutions = shadowed
+		}
+	}
+	return mapChildren(t, func(child Type) Type { return substituteTemplates(child, substitutions) })
+}
+
+// instantiate picks the template arguments that make the generic callable c
+// fit the signature of target and returns c with them substituted.
+func (e *Env) instantiate(c *CallableType, target *CallableType) (*CallableType, *Reason) {
+	inf := e.newInference(c.Templates)
+	for i, parameter := range target.Parameters {
+		if actual := paramAt(c.Parameters, i); actual != nil {
+			inf.unify(actual.Type, parameter.Type, false)
+		}
+	}
+	if _, ok := target.ReturnType.(*VoidType); !ok {
+		inf.unify(c.ReturnType, target.ReturnType, true)
+	}
+	substitutions := make(map[string]Type, len(c.Templates))
+	for _, template := range c.Templates {
+		var bound Type
+		if template.Bound != nil {
+			resolved, err := e.Resolve(template.Bound)
+			if err != nil {
+				return nil, newReason(err.Error())
+			}
+			bound = replaceTemplates(resolved, substitutions)
+		}
+		bounds := inf.templates[template.Name]
+		var inferred Type
+		switch {
+		case len(bounds.lower) > 0:
+			inferred = e.Simplify(union(bounds.lower))
+		case len(bounds.upper) > 0:
+			inferred = bounds.upper[0]
+			for _, upper := range bounds.upper[1:] {
+				inferred = e.Meet(inferred, upper)
+			}
+		case bound != nil:
+			inferred = bound
+		default:
+			inferred = NewMixedType()
+		}
+		if bound != nil && !e.IsSubtype(inferred, bound) {
+			return nil, newReason(fmt.Sprintf("%s does not satisfy the bound %s of template %s", inferred, bound, template.Name))
+		}
+		substitutions[template.Name] = inferred
+	}
+	instantiated := &CallableType{
+		Parameters: c.Parameters,
+		ReturnType: c.ReturnType,
+		Closure:    c.Closure,
+		Pure:       c.Pure,
+	}
+	return substituteTemplates(instantiated, substitutions).(*CallableType), nil
+}
@@ -0,0 +1,91 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func TestSubstitute(t *testing.T) {
+	params := []*parser.TemplateParam{
+		parser.NewTemplateParam("T", parser.NewSimpleNode("object")),
+		{Name: "U", Default: parser.NewSimpleNode("int")},
+		parser.NewTemplateParam("V", parser.NewSimpleNode("T")),
+	}
+	node := parser.NewCallableNode(
+		parser.NewGenericNode("list", []parser.Node{parser.NewSimpleNode("U")}),
+		[]*parser.ParamNode{
+			parser.NewParam(parser.NewSimpleNode("T")),
+			parser.NewOptionalParam(parser.NewSimpleNode("V")),
+		},
+	)
+	tests := []struct {
+		name          string
+		substitutions map[string]parser.Node
+		want          string
+	}{
+		{
+			"all substituted",
+			map[string]parser.Node{
+				"T": parser.NewSimpleNode("Foo"),
+				"U": parser.NewSimpleNode("string"),
+				"V": parser.NewSimpleNode("Foo"),
+			},
+			"callable(Foo, Foo=): list<string>",
+		},
+		{
+			"defaults and bounds",
+			map[string]parser.Node{"T": parser.NewSimpleNode("Foo")},
+			"callable(Foo, Foo=): list<int>",
+		},
+		{
+			"nothing substituted",
+			nil,
+			"callable(object, object=): list<int>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := types.Substitute(node, params, test.substitutions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != test.want {
+				t.Errorf("Substitute(%s) = %s, want %s", node, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSubstitute_Errors(t *testing.T) {
+	params := []*parser.TemplateParam{
+		parser.NewTemplateParam("T", parser.NewSimpleNode("object")),
+		parser.NewTemplateParam("K", parser.NewSimpleNode("array-key")),
+	}
+	tests := []struct {
+		substitutions map[string]parser.Node
+		want          string
+	}{
+		{
+			map[string]parser.Node{"T": parser.NewSimpleNode("int")},
+			"int does not satisfy the bound object of template T",
+		},
+		{
+			map[string]parser.Node{"K": parser.NewSimpleNode("float")},
+			"float does not satisfy the bound array-key of template K",
+		},
+		{
+			map[string]parser.Node{"X": parser.NewSimpleNode("int")},
+			"unknown template X",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			_, err := types.Substitute(parser.NewSimpleNode("T"), params, test.substitutions)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("Substitute() error = %v, want %s", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,571 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MidnightDesign/php-types-go/parser"
+)
+
+type Type interface {
+	fmt.Stringer
+}
+
+type MixedType struct{}
+
+type NeverType struct{}
+
+type VoidType struct{}
+
+type NullType struct{}
+
+type ResourceType struct{}
+
+type BoolType struct{}
+
+type BoolLiteralType struct {
+	Value bool
+}
+
+type IntType struct {
+	Min *int
+	Max *int
+}
+
+type IntLiteralType struct {
+	Value int
+}
+
+type FloatType struct{}
+
+type StringType struct {
+	NonEmpty bool
+	Numeric  bool
+}
+
+type StringLiteralType struct {
+	Value string
+}
+
+type ClassStringType struct {
+	Class Type
+}
+
+type ArrayType struct {
+	Key      Type
+	Value    Type
+	List     bool
+	NonEmpty bool
+}
+
+type IterableType struct {
+	Key   Type
+	Value Type
+}
+
+// ShapeType is an array shape. An unsealed shape may hold keys besides its
+// members; ExtraKey and ExtraValue restrict them and default to array-key
+// and mixed. A list shape is written list{...} and is always a list.
+type ShapeType struct {
+	Members    []*ShapeMember
+	Sealed     bool
+	ExtraKey   Type
+	ExtraValue Type
+	List       bool
+}
+
+type ObjectShapeType struct {
+	Properties []*ShapeMember
+}
+
+type ShapeMember struct {
+	Key      string
+	Value    Type
+	Optional bool
+	Node     *parser.MemberNode
+}
+
+type ObjectType struct {
+	Class         string
+	TypeArguments []Type
+	Variances     []parser.Variance
+}
+
+type EnumCaseType struct {
+	Enum string
+	Case string
+}
+
+type CallableType struct {
+	Templates  []*parser.TemplateParam
+	Parameters []*CallableParam
+	ReturnType Type
+	Closure    bool
+	Pure       bool
+}
+
+type CallableParam struct {
+	Type     Type
+	Optional bool
+	Variadic bool
+	ByRef    bool
+	Name     string
+	Node     *parser.ParamNode
+}
+
+// TemplateType is a template parameter of a generic callable. It stands for
+// one fixed but unknown type within its bound.
+type TemplateType struct {
+	Name  string
+	Bound Type
+}
+
+type UnionType struct {
+	Types []Type
+}
+
+type IntersectionType struct {
+	Types []Type
+}
+
+func typeList(list []Type) string {
+	elements := make([]string, len(list))
+	for i, element := range list {
+		elements[i] = element.String()
+	}
+	return strings.Join(elements, ", ")
+}
+
+func (t *MixedType) String() string {
+	return "mixed"
+}
+
+func (t *NeverType) String() string {
+	return "never"
+}
+
+func (t *VoidType) String() string {
+	return "void"
+}
+
+func (t *NullType) String() string {
+	return "null"
+}
+
+func (t *ResourceType) String() string {
+	return "resource"
+}
+
+func (t *BoolType) String() string {
+	return "bool"
+}
+
+func (t *BoolLiteralType) String() string {
+	if t.Value {
+		return "true"
+	}
+	return "false"
+}
+
+func (t *IntType) String() string {
+	switch {
+	case t.Min == nil && t.Max == nil:
+		return "int"
+	case t.Max == nil && *t.Min == 1:
+		return "positive-int"
+	case t.Max == nil && *t.Min == 0:
+		return "non-negative-int"
+	case t.Min == nil && *t.Max == -1:
+		return "negative-int"
+	case t.Min == nil && *t.Max == 0:
+		return "non-positive-int"
+	}
+	return fmt.Sprintf("int<%s, %s>", intBound(t.Min, "min"), intBound(t.Max, "max"))
+}
+
+func intBound(bound *int, unbounded string) string {
+	if bound == nil {
+		return unbounded
+	}
+	return strconv.Itoa(*bound)
+}
+
+func (t *IntLiteralType) String() string {
+	return strconv.Itoa(t.Value)
+}
+
+func (t *FloatType) String() string {
+	return "float"
+}
+
+func (t *StringType) String() string {
+	if t.Numeric {
+		return "numeric-string"
+	}
+	if t.NonEmpty {
+		return "non-empty-string"
+	}
+	return "string"
+}
+
+func (t *StringLiteralType) String() string {
+	return fmt.Sprintf("\"%s\"", t.Value)
+}
+
+func (t *ClassStringType) String() string {
+	if t.Class == nil {
+		return "class-string"
+	}
+	return fmt.Sprintf("class-string<%s>", t.Class)
+}
+
+func (t *ArrayType) String() string {
+	name := "array"
+	if t.List {
+		name = "list"
+	}
+	if t.NonEmpty {
+		name = "non-empty-" + name
+	}
+	if t.List {
+		return fmt.Sprintf("%s<%s>", name, t.Value)
+	}
+	return fmt.Sprintf("%s<%s, %s>", name, t.Key, t.Value)
+}
+
+func (t *IterableType) String() string {
+	return fmt.Sprintf("iterable<%s, %s>", t.Key, t.Value)
+}
+
+func (t *ShapeType) String() string {
+	members := shapeMembers(t.Members)
+	if !t.Sealed {
+		extra := "..."
+		switch {
+		case t.ExtraValue == nil:
+		case t.ExtraKey == nil:
+			extra = fmt.Sprintf("...<%s>", t.ExtraValue)
+		default:
+			extra = fmt.Sprintf("...<%s, %s>", t.ExtraKey, t.ExtraValue)
+		}
+		if members == "" {
+			members = extra
+		} else {
+			members += ", " + extra
+		}
+	}
+	name := "array"
+	if t.List {
+		name = "list"
+	}
+	return fmt.Sprintf("%s{%s}", name, members)
+}
+
+// extra returns the key and value types of the keys an unsealed shape may
+// hold besides its members.
+func (t *ShapeType) extra() (Type, Type) {
+	key, value := t.ExtraKey, t.ExtraValue
+	switch {
+	case key != nil:
+	case t.List:
+		key = NewIntRangeType(intPtr(0), nil)
+	default:
+		key = NewArrayKeyType()
+	}
+	if value == nil {
+		value = NewMixedType()
+	}
+	return key, value
+}
+
+func (t *ObjectShapeType) String() string {
+	return fmt.Sprintf("object{%s}", shapeMembers(t.Properties))
+}
+
+func shapeMembers(members []*ShapeMember) string {
+	positional := true
+	for i, member := range members {
+		if member.Key != strconv.Itoa(i) || member.Optional {
+			positional = false
+			break
+		}
+	}
+	elements := make([]string, len(members))
+	for i, member := range members {
+		if positional {
+			elements[i] = member.Value.String()
+			continue
+		}
+		elements[i] = member.String()
+	}
+	return strings.Join(elements, ", ")
+}
+
+func (m *ShapeMember) String() string {
+	if m.Optional {
+		return fmt.Sprintf("%s?: %s", formatKey(m.Key), m.Value)
+	}
+	return fmt.Sprintf("%s: %s", formatKey(m.Key), m.Value)
+}
+
+func (t *ObjectType) String() string {
+	name := t.Class
+	if name == "" {
+		name = "object"
+	}
+	if len(t.TypeArguments) == 0 {
+		return name
+	}
+	arguments := make([]string, len(t.TypeArguments))
+	for i, argument := range t.TypeArguments {
+		switch t.variance(i) {
+		case parser.Invariant:
+			arguments[i] = argument.String()
+		case parser.Bivariant:
+			arguments[i] = "*"
+		default:
+			arguments[i] = fmt.Sprintf("%s %s", t.variance(i), argument)
+		}
+	}
+	return fmt.Sprintf("%s<%s>", name, strings.Join(arguments, ", "))
+}
+
+// variance returns the call-site variance of the i-th type argument.
+func (t *ObjectType) variance(i int) parser.Variance {
+	if i < len(t.Variances) {
+		return t.Variances[i]
+	}
+	return parser.Invariant
+}
+
+func (t *EnumCaseType) String() string {
+	return fmt.Sprintf("%s::%s", t.Enum, t.Case)
+}
+
+func (t *CallableType) String() string {
+	if t.ReturnType == nil {
+		return t.Keyword()
+	}
+	parameters := make([]string, len(t.Parameters))
+	for i, parameter := range t.Parameters {
+		parameters[i] = parameter.String()
+	}
+	keyword := t.Keyword()
+	if len(t.Templates) > 0 {
+		templates := make([]string, len(t.Templates))
+		for i, template := range t.Templates {
+			templates[i] = template.String()
+		}
+		keyword = fmt.Sprintf("%s<%s>", keyword, strings.Join(templates, ", "))
+	}
+	return fmt.Sprintf("%s(%s): %s", keyword, strings.Join(parameters, ", "), t.ReturnType)
+}
+
+// Keyword returns the name the callable is written with, e.g. pure-Closure.
+func (t *CallableType) Keyword() string {
+	keyword := "callable"
+	if t.Closure {
+		keyword = "Closure"
+	}
+	if t.Pure {
+		keyword = "pure-" + keyword
+	}
+	return keyword
+}
+
+func (p *CallableParam) String() string {
+	s := p.Type.String()
+	if p.ByRef || p.Variadic || p.Name != "" {
+		s += " "
+		if p.ByRef {
+			s += "&"
+		}
+		if p.Variadic {
+			s += "..."
+		}
+		if p.Name != "" {
+			s += "$" + p.Name
+		}
+	}
+	if p.Optional {
+		s += "="
+	}
+	return s
+}
+
+func (t *TemplateType) String() string {
+	return t.Name
+}
+
+func (t *UnionType) String() string {
+	elements := make([]string, len(t.Types))
+	for i, element := range t.Types {
+		elements[i] = element.String()
+	}
+	return strings.Join(elements, " | ")
+}
+
+func (t *IntersectionType) String() string {
+	elements := make([]string, len(t.Types))
+	for i, element := range t.Types {
+		elements[i] = element.String()
+	}
+	return strings.Join(elements, " & ")
+}
+
+func NewMixedType() Type {
+	return &MixedType{}
+}
+
+func NewNeverType() Type {
+	return &NeverType{}
+}
+
+func NewVoidType() Type {
+	return &VoidType{}
+}
+
+func NewNullType() Type {
+	return &NullType{}
+}
+
+func NewResourceType() Type {
+	return &ResourceType{}
+}
+
+func NewBoolType() Type {
+	return &BoolType{}
+}
+
+func NewBoolLiteralType(value bool) Type {
+	return &BoolLiteralType{Value: value}
+}
+
+func NewIntType() Type {
+	return &IntType{}
+}
+
+func NewIntRangeType(min, max *int) Type {
+	return &IntType{Min: min, Max: max}
+}
+
+func NewIntLiteralType(value int) Type {
+	return &IntLiteralType{Value: value}
+}
+
+func NewFloatType() Type {
+	return &FloatType{}
+}
+
+func NewStringType() Type {
+	return &StringType{}
+}
+
+func NewNonEmptyStringType() Type {
+	return &StringType{NonEmpty: true}
+}
+
+func NewNumericStringType() Type {
+	return &StringType{NonEmpty: true, Numeric: true}
+}
+
+func NewStringLiteralType(value string) Type {
+	return &StringLiteralType{Value: value}
+}
+
+func NewClassStringType(class Type) Type {
+	return &ClassStringType{Class: class}
+}
+
+func NewArrayType(key, value Type) Type {
+	return &ArrayType{Key: key, Value: value}
+}
+
+func NewNonEmptyArrayType(key, value Type) Type {
+	return &ArrayType{Key: key, Value: value, NonEmpty: true}
+}
+
+func NewListType(value Type) Type {
+	return &ArrayType{Key: NewIntRangeType(intPtr(0), nil), Value: value, List: true}
+}
+
+func NewNonEmptyListType(value Type) Type {
+	return &ArrayType{Key: NewIntRangeType(intPtr(0), nil), Value: value, List: true, NonEmpty: true}
+}
+
+func NewIterableType(key, value Type) Type {
+	return &IterableType{Key: key, Value: value}
+}
+
+func NewShapeType(members []*ShapeMember) Type {
+	return &ShapeType{Members: members, Sealed: true}
+}
+
+func NewListShapeType(members []*ShapeMember) Type {
+	return &ShapeType{Members: members, Sealed: true, List: true}
+}
+
+func NewUnsealedShapeType(members []*ShapeMember, extraKey, extraValue Type) Type {
+	return &ShapeType{Members: members, ExtraKey: extraKey, ExtraValue: extraValue}
+}
+
+func NewObjectShapeType(properties []*ShapeMember) Type {
+	return &ObjectShapeType{Properties: properties}
+}
+
+func NewShapeMember(key string, value Type) *ShapeMember {
+	return &ShapeMember{Key: key, Value: value}
+}
+
+func NewOptionalShapeMember(key string, value Type) *ShapeMember {
+	return &ShapeMember{Key: key, Value: value, Optional: true}
+}
+
+func NewObjectType(class string, typeArguments ...Type) Type {
+	return &ObjectType{Class: class, TypeArguments: typeArguments}
+}
+
+func NewEnumCaseType(enum, name string) Type {
+	return &EnumCaseType{Enum: enum, Case: name}
+}
+
+func NewCallableType(returnType Type, parameters []*CallableParam) Type {
+	return &CallableType{Parameters: parameters, ReturnType: returnType}
+}
+
+func NewAnyCallableType() Type {
+	return &CallableType{}
+}
+
+func NewCallableParam(t Type) *CallableParam {
+	return &CallableParam{Type: t}
+}
+
+func NewOptionalCallableParam(t Type) *CallableParam {
+	return &CallableParam{Type: t, Optional: true}
+}
+
+func NewTemplateType(name string, bound Type) Type {
+	return &TemplateType{Name: name, Bound: bound}
+}
+
+func NewVariadicCallableParam(t Type) *CallableParam {
+	return &CallableParam{Type: t, Variadic: true}
+}
+
+func NewUnionType(first Type, second Type, other ...Type) Type {
+	return &UnionType{Types: append([]Type{first, second}, other...)}
+}
+
+func NewIntersectionType(first Type, second Type, other ...Type) Type {
+	return &IntersectionType{Types: append([]Type{first, second}, other...)}
+}
+
+func NewArrayKeyType() Type {
+	return NewUnionType(NewIntType(), NewStringType())
+}
+
+func intPtr(i int) *int {
+	return &i
+}
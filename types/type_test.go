@@ -0,0 +1,60 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/MidnightDesign/php-types-go/types"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestType_String(t *testing.T) {
+	tests := []struct {
+		t    types.Type
+		want string
+	}{
+		{types.NewIntType(), "int"},
+		{types.NewIntRangeType(intPtr(1), nil), "positive-int"},
+		{types.NewIntRangeType(nil, intPtr(0)), "non-positive-int"},
+		{types.NewIntRangeType(intPtr(-5), intPtr(5)), "int<-5, 5>"},
+		{types.NewIntRangeType(nil, intPtr(10)), "int<min, 10>"},
+		{types.NewIntRangeType(intPtr(2), nil), "int<2, max>"},
+		{types.NewNumericStringType(), "numeric-string"},
+		{types.NewStringLiteralType("foo"), "\"foo\""},
+		{types.NewBoolLiteralType(false), "false"},
+		{types.NewListType(types.NewStringType()), "list<string>"},
+		{types.NewNonEmptyArrayType(types.NewIntType(), types.NewStringType()), "non-empty-array<int, string>"},
+		{types.NewArrayType(types.NewArrayKeyType(), types.NewMixedType()), "array<int | string, mixed>"},
+		{types.NewClassStringType(types.NewObjectType("Foo")), "class-string<Foo>"},
+		{types.NewObjectType("Collection", types.NewIntType()), "Collection<int>"},
+		{types.NewObjectType(""), "object"},
+		{types.NewShapeType(nil), "array{}"},
+		{types.NewShapeType([]*types.ShapeMember{
+			types.NewShapeMember("0", types.NewIntType()),
+			types.NewShapeMember("1", types.NewStringType()),
+		}), "array{int, string}"},
+		{types.NewShapeType([]*types.ShapeMember{
+			types.NewShapeMember("foo", types.NewIntType()),
+			types.NewOptionalShapeMember("bar", types.NewStringType()),
+		}), "array{foo: int, bar?: string}"},
+		{types.NewObjectShapeType([]*types.ShapeMember{
+			types.NewShapeMember("foo", types.NewIntType()),
+		}), "object{foo: int}"},
+		{types.NewAnyCallableType(), "callable"},
+		{types.NewCallableType(types.NewVoidType(), []*types.CallableParam{
+			types.NewCallableParam(types.NewIntType()),
+			types.NewOptionalCallableParam(types.NewStringType()),
+		}), "callable(int, string=): void"},
+		{types.NewUnionType(types.NewIntType(), types.NewNullType()), "int | null"},
+		{types.NewIntersectionType(types.NewObjectType("Foo"), types.NewObjectType("Bar")), "Foo & Bar"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.t.String(); got != test.want {
+				t.Errorf("Type.String() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}